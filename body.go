@@ -0,0 +1,126 @@
+package husky
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+)
+
+// ErrFormTooLarge is returned by ParseForm when an url encoded body is larger
+// than MaxFormSize
+var ErrFormTooLarge = errors.New("husky: form body too large")
+
+// MaxFormSize is the maximum size of url encoded bodies parsed into form values
+var MaxFormSize int64 = 10 << 20
+
+// Body reads the request body once and keeps it in memory, Request.Body is
+// reset on every call so middleware and handlers can all read it. Use the
+// BodyLimit middleware to bound the size of buffered bodies.
+func (ctx *CTX) Body() ([]byte, error) {
+	if ctx.body == nil {
+		if ctx.Request.Body == nil {
+			ctx.body = []byte{}
+		} else {
+			b, err := io.ReadAll(ctx.Request.Body)
+			if err != nil {
+				return nil, err
+			}
+			ctx.body = b
+		}
+	}
+
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(ctx.body))
+	return ctx.body, nil
+}
+
+// FormValue returns the first value of the named form field or query param,
+// the body is parsed on first use and stays readable through Body
+func (ctx *CTX) FormValue(name string) string {
+	ctx.parseForm()
+
+	if value := ctx.Request.Form.Get(name); value != "" {
+		return value
+	}
+
+	if ctx.multipartForm != nil {
+		if values := ctx.multipartForm.Value[name]; len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// ParseForm parses the form values used by FormValue and GetParam and returns
+// the parse error, such as ErrFormTooLarge. Bodies larger than MaxFormSize
+// are not parsed, the query params are still available.
+func (ctx *CTX) ParseForm() error {
+	ctx.parseForm()
+	return ctx.formErr
+}
+
+// parseForm parses the URL query and url encoded bodies on first use and adds
+// the values to Params, path and query params keep precedence
+func (ctx *CTX) parseForm() {
+	if ctx.formParsed {
+		return
+	}
+	ctx.formParsed = true
+
+	// buffer url encoded bodies so ParseForm does not consume them
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		ctx.formErr = ctx.bufferForm()
+	}
+
+	if ctx.formErr != nil {
+		ctx.Request.Form = ctx.Request.URL.Query()
+	} else {
+		ctx.formErr = ctx.Request.ParseForm()
+		if ctx.body != nil {
+			ctx.Body()
+		}
+	}
+
+	if ctx.Params == nil {
+		ctx.Params = make(map[string]string)
+	}
+
+	for k, v := range ctx.Request.Form {
+		if _, exists := ctx.Params[k]; !exists && len(v) > 0 {
+			ctx.Params[k] = v[0]
+		}
+	}
+}
+
+// bufferForm buffers an url encoded body of at most MaxFormSize bytes, a
+// larger body is left readable in full
+func (ctx *CTX) bufferForm() error {
+	if ctx.body == nil && ctx.Request.Body != nil {
+		body := ctx.Request.Body
+
+		b, err := io.ReadAll(io.LimitReader(body, MaxFormSize+1))
+		if err != nil {
+			return err
+		}
+
+		if int64(len(b)) > MaxFormSize {
+			ctx.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(b), body), body}
+			return ErrFormTooLarge
+		}
+
+		ctx.body = b
+	}
+
+	if int64(len(ctx.body)) > MaxFormSize {
+		return ErrFormTooLarge
+	}
+
+	// ParseForm reads the buffered copy, which is reset once it is parsed
+	ctx.Body()
+	return nil
+}
@@ -0,0 +1,122 @@
+package husky
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingLeavesBodyUnread(t *testing.T) {
+	h := New()
+
+	h.POST("/users/:id", func(ctx *CTX) error {
+		b, _ := io.ReadAll(ctx.Request.Body)
+		return ctx.String(200, ctx.GetParam("id")+" "+string(b))
+	})
+
+	r := httptest.NewRequest("POST", "/users/5", strings.NewReader(`{"name":"husky"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, `5 {"name":"husky"}`, w.Body.String())
+}
+
+func TestFormParamsAreParsedLazily(t *testing.T) {
+	h := New()
+
+	h.POST("/users/:id", func(ctx *CTX) error {
+		assert.False(t, ctx.formParsed)
+
+		name, id := ctx.GetParam("name"), ctx.GetParam("id")
+
+		// the body is still readable after parsing the form
+		b, _ := io.ReadAll(ctx.Request.Body)
+		return ctx.String(200, id+" "+name+" "+string(b))
+	})
+
+	r := httptest.NewRequest("POST", "/users/5", strings.NewReader("name=husky&id=6"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "5 husky name=husky&id=6", w.Body.String())
+}
+
+func TestBodyIsReReadable(t *testing.T) {
+	h := New()
+
+	signature := func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			b, err := ctx.Body()
+			if err != nil || string(b) != "payload" {
+				return ctx.JSON(401, "Unauthorized")
+			}
+
+			return next(ctx)
+		}
+	}
+
+	h.POST("/hooks", func(ctx *CTX) error {
+		b, _ := io.ReadAll(ctx.Request.Body)
+		again, _ := ctx.Body()
+		return ctx.String(200, string(b)+" "+string(again))
+	}, signature)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/hooks", strings.NewReader("payload")))
+
+	assert.Equal(t, "payload payload", w.Body.String())
+}
+
+func TestFormValue(t *testing.T) {
+	h := New()
+
+	r := httptest.NewRequest("POST", "/?page=2", strings.NewReader("_csrf=token"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := h.NewContext(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "token", c.FormValue("_csrf"))
+	assert.Equal(t, "2", c.FormValue("page"))
+	assert.Equal(t, "", c.FormValue("missing"))
+
+	b, _ := c.Body()
+	assert.Equal(t, "_csrf=token", string(b))
+}
+
+func TestFormValueTooLarge(t *testing.T) {
+	defer func(size int64) { MaxFormSize = size }(MaxFormSize)
+	MaxFormSize = 16
+
+	h := New()
+	body := "name=" + strings.Repeat("a", 32)
+
+	r := httptest.NewRequest("POST", "/?page=2", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := h.NewContext(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "", c.FormValue("name"))
+	assert.Equal(t, "2", c.FormValue("page"))
+	assert.Equal(t, ErrFormTooLarge, c.ParseForm())
+
+	// the body is left unparsed and readable in full
+	b, err := c.Body()
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+func TestRouterMatch(t *testing.T) {
+	h := New()
+	h.GET("/users/:id", handler)
+
+	route, params, found := h.Router.Match("GET", "/users/5")
+	assert.True(t, found)
+	assert.Equal(t, "/users/:id", route.Endpoint)
+	assert.Equal(t, map[string]string{"id": "5"}, params)
+
+	_, _, found = h.Router.Match("POST", "/users/5")
+	assert.False(t, found)
+}
@@ -2,6 +2,7 @@ package husky
 
 import (
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,3 +18,20 @@ func (configuration *Configuration) Load() map[string]string {
 	}
 	return config
 }
+
+// duration parses a time.Duration config value such as "30s", returning
+// fallback when the key is missing or invalid
+func duration(config map[string]string, key string, fallback time.Duration) time.Duration {
+	value, ok := config[key]
+	if !ok || value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("WARNING: Invalid duration for %s: %s", key, value)
+		return fallback
+	}
+
+	return d
+}
@@ -1,15 +1,48 @@
 package husky
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"io"
 	"net/http"
+	"reflect"
+	"regexp"
+	"sync"
 )
 
+// MIME types used by the response renderers
+const (
+	MIMEApplicationJSON       = "application/json"
+	MIMEApplicationJavaScript = "application/javascript"
+	MIMEApplicationXML        = "application/xml"
+	MIMETextXML               = "text/xml"
+	MIMETextHTML              = "text/html"
+	MIMETextPlain             = "text/plain"
+	MIMEOctetStream           = "application/octet-stream"
+
+	charsetUTF8 = ";charset=utf-8"
+)
+
+// jsonpCallback restricts JSONP callbacks to JavaScript identifiers
+var jsonpCallback = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$.]*$`)
+
 // CTX (Context) struct
 type CTX struct {
 	Request  *http.Request
 	Response *Response
 	Params   map[string]string
+	husky    *Husky
+
+	body          []byte
+	cleanups      *cleanupQueue
+	err           error
+	formErr       error
+	formParsed    bool
+	multipartForm *MultipartForm
+	path          string
+	services      map[reflect.Type]reflect.Value
 }
 
 // AddParams adds parameters to context
@@ -25,6 +58,93 @@ func (ctx *CTX) AddParams(params map[string]string) {
 	return
 }
 
+// cleanupQueue holds the cleanup functions of a request, it is kept behind a
+// pointer so CTX values can still be copied
+type cleanupQueue struct {
+	mu    sync.Mutex
+	fns   []func()
+	due   bool
+	holds int
+}
+
+// OnCleanup registers a function to run once the request has been served,
+// middleware use it to release pooled resources the handler may not close
+func (ctx *CTX) OnCleanup(fn func()) {
+	ctx.cleanups.mu.Lock()
+	defer ctx.cleanups.mu.Unlock()
+
+	ctx.cleanups.fns = append(ctx.cleanups.fns, fn)
+}
+
+// Hold postpones the cleanup of the request, such as removing multipart temp
+// files and closing request scoped services, until release is called.
+// Middleware running the handler in another goroutine hold the CTX until
+// that goroutine exits.
+func (ctx *CTX) Hold() (release func()) {
+	queue := ctx.cleanups
+
+	queue.mu.Lock()
+	queue.holds++
+	queue.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			queue.mu.Lock()
+			queue.holds--
+			due := queue.holds == 0 && queue.due
+			queue.mu.Unlock()
+
+			if due {
+				ctx.Cleanup()
+			}
+		})
+	}
+}
+
+// Cleanup runs the registered cleanup functions in reverse order, or once
+// the last Hold is released. ServeHTTP calls it after the request is served,
+// code creating a CTX with NewContext calls it when done with the CTX.
+func (ctx *CTX) Cleanup() {
+	queue := ctx.cleanups
+
+	queue.mu.Lock()
+	if queue.holds > 0 {
+		queue.due = true
+		queue.mu.Unlock()
+		return
+	}
+
+	fns := queue.fns
+	queue.fns = nil
+	queue.due = false
+	queue.mu.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+}
+
+// Husky returns the service handling the request
+func (ctx *CTX) Husky() *Husky {
+	return ctx.husky
+}
+
+// Path returns the endpoint pattern of the matched route, e.g. "/users/:id"
+func (ctx *CTX) Path() string {
+	return ctx.path
+}
+
+// Context returns the request's context.Context
+func (ctx *CTX) Context() context.Context {
+	return ctx.Request.Context()
+}
+
+// SetContext replaces the request's context.Context
+func (ctx *CTX) SetContext(c context.Context) {
+	ctx.Request = ctx.Request.WithContext(c)
+}
+
 // Code writes header with HTTP code
 func (ctx *CTX) Code(code int) (err error) {
 	ctx.Response.WriteHeader(code)
@@ -46,27 +166,88 @@ func (ctx *CTX) JSON(code int, i interface{}) (err error) {
 	return
 }
 
+// Cookie returns the named cookie from the request
+func (ctx *CTX) Cookie(name string) (*http.Cookie, error) {
+	return ctx.Request.Cookie(name)
+}
+
+// Cookies returns all cookies sent with the request
+func (ctx *CTX) Cookies() []*http.Cookie {
+	return ctx.Request.Cookies()
+}
+
+// SecureCookie returns the decoded value of a cookie set with SetSecureCookie
+func (ctx *CTX) SecureCookie(codec *CookieCodec, name string) (string, error) {
+	cookie, err := ctx.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return codec.Decode(name, cookie.Value)
+}
+
+// Blob returns the bytes as a response with the given content type
+func (ctx *CTX) Blob(code int, contentType string, b []byte) (err error) {
+	ctx.Response.Header().Set("Content-Type", contentType)
+	ctx.Response.WriteHeader(code)
+	_, err = ctx.Response.Write(b)
+	return
+}
+
+// Stream copies the reader to the response with the given content type
+func (ctx *CTX) Stream(code int, contentType string, r io.Reader) (err error) {
+	ctx.Response.Header().Set("Content-Type", contentType)
+	ctx.Response.WriteHeader(code)
+	_, err = io.Copy(ctx.Response, r)
+	return
+}
+
 // GetHeader returns specified header
 func (ctx *CTX) GetHeader(header string) string {
 	return ctx.Request.Header.Get(header)
 }
 
 // GetParam return specified paramater
+// Form values of the body are parsed on first access
 func (ctx *CTX) GetParam(i string) string {
+	ctx.parseForm()
 	return ctx.Params[i]
 }
 
 // GetParams returns all stored parameters
 func (ctx *CTX) GetParams() map[string]string {
+	ctx.parseForm()
 	return ctx.Params
 }
 
 // HasParam checks if param is set
 func (ctx *CTX) HasParam(param string) bool {
+	ctx.parseForm()
 	_, isSet := ctx.Params[param]
 	return isSet
 }
 
+// Error passes err to the ErrorHandler of the service once, middleware can
+// call it to observe the final response before the request completes
+func (ctx *CTX) Error(err error) {
+	if err == nil || ctx.err != nil {
+		return
+	}
+	ctx.err = err
+
+	if ctx.husky != nil && ctx.husky.ErrorHandler != nil {
+		ctx.husky.ErrorHandler(err, ctx)
+		return
+	}
+
+	DefaultErrorHandler(err, ctx)
+}
+
+// Err returns the error passed to Error
+func (ctx *CTX) Err() error {
+	return ctx.err
+}
+
 // HTTPError returns a text/html error with requested code
 func (ctx *CTX) HTTPError(code int, message string) (err error) {
 	ctx.Response.Header().Set("Content-Type", "text/html;charset=utf-8")
@@ -75,20 +256,99 @@ func (ctx *CTX) HTTPError(code int, message string) (err error) {
 	return
 }
 
+// JSONP returns response as serialized JSON wrapped in a JavaScript callback
+func (ctx *CTX) JSONP(code int, callback string, i interface{}) (err error) {
+	if !jsonpCallback.MatchString(callback) {
+		return ctx.HTTPError(400, "Invalid JSONP callback")
+	}
+
+	b, err := json.Marshal(i)
+	if err != nil {
+		ctx.HTTPError(500, err.Error())
+		return
+	}
+
+	ctx.Response.Header().Set("Content-Type", MIMEApplicationJavaScript+charsetUTF8)
+	ctx.Response.WriteHeader(code)
+	_, err = ctx.Response.Write([]byte(callback + "(" + string(b) + ");"))
+	return
+}
+
+// JSONPretty returns response as indented JSON
+func (ctx *CTX) JSONPretty(code int, i interface{}, indent string) (err error) {
+	b, err := json.MarshalIndent(i, "", indent)
+	if err != nil {
+		ctx.HTTPError(500, err.Error())
+		return
+	}
+
+	return ctx.Blob(code, MIMEApplicationJSON, b)
+}
+
+// NoContent writes the HTTP code without a body
+func (ctx *CTX) NoContent(code int) error {
+	ctx.Response.WriteHeader(code)
+	return nil
+}
+
 // Redirect returns a HTTP code
 func (ctx *CTX) Redirect(code int, uri string) (err error) {
-	http.Redirect(ctx.Response.Writer, ctx.Request, uri, code)
+	http.Redirect(ctx.Response, ctx.Request, uri, code)
 	return nil
 }
 
+// Render renders the named template with the Renderer of the Husky service
+// The template is rendered to a buffer first so errors never send a partial page
+func (ctx *CTX) Render(code int, name string, data interface{}) error {
+	if ctx.husky == nil || ctx.husky.Renderer == nil {
+		return ErrRendererNotSet
+	}
+
+	var b bytes.Buffer
+	if err := ctx.husky.Renderer.Render(&b, name, data, ctx); err != nil {
+		return err
+	}
+
+	return ctx.Blob(code, MIMETextHTML+charsetUTF8, b.Bytes())
+}
+
+// SetCookie adds a Set-Cookie header to the response
+func (ctx *CTX) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(ctx.Response, cookie)
+}
+
+// SetSecureCookie signs or encrypts the cookie value with the codec before
+// adding it to the response
+func (ctx *CTX) SetSecureCookie(codec *CookieCodec, cookie *http.Cookie) (err error) {
+	encoded := *cookie
+	if encoded.Value, err = codec.Encode(cookie.Name, cookie.Value); err != nil {
+		return
+	}
+
+	http.SetCookie(ctx.Response, &encoded)
+	return
+}
+
 // SetHeader adds header to response
 func (ctx *CTX) SetHeader(k string, v string) {
 	ctx.Response.Header().Set(k, v)
 }
 
+// String returns a text/html response
 func (ctx *CTX) String(code int, s string) (err error) {
 	ctx.Response.Header().Set("Content-Type", "text/html;charset=utf-8")
 	ctx.Response.WriteHeader(code)
 	_, err = ctx.Response.Write([]byte(s))
 	return
 }
+
+// XML returns response as serialized XML
+func (ctx *CTX) XML(code int, i interface{}) (err error) {
+	b, err := xml.Marshal(i)
+	if err != nil {
+		ctx.HTTPError(500, err.Error())
+		return
+	}
+
+	return ctx.Blob(code, MIMEApplicationXML+charsetUTF8, append([]byte(xml.Header), b...))
+}
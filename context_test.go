@@ -1,6 +1,7 @@
 package husky
 
 import (
+	"html/template"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -175,3 +176,147 @@ func TestCodeFunc(t *testing.T) {
 
 	assert.Nil(t, g.GetContext().Code(200))
 }
+
+func TestCookies(t *testing.T) {
+	h := New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	w := httptest.NewRecorder()
+
+	c := h.NewContext(w, r)
+
+	cookie, err := c.Cookie("theme")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "dark", cookie.Value)
+	}
+	assert.Len(t, c.Cookies(), 1)
+
+	c.SetCookie(&http.Cookie{Name: "theme", Value: "light"})
+	assert.Equal(t, "theme=light", w.Header().Get("Set-Cookie"))
+}
+
+func TestSecureCookies(t *testing.T) {
+	h := New()
+	codec, _ := NewSignedCookieCodec([]byte("secret"))
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	err := c.SetSecureCookie(codec, &http.Cookie{Name: "cart", Value: "3 items"})
+	assert.NoError(t, err)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	c = h.NewContext(httptest.NewRecorder(), r)
+
+	value, err := c.SecureCookie(codec, "cart")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "3 items", value)
+	}
+}
+
+func TestXMLResponse(t *testing.T) {
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	type item struct {
+		Name string `xml:"name"`
+	}
+
+	err := c.XML(200, item{"husky"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "application/xml;charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<item><name>husky</name></item>", w.Body.String())
+	}
+}
+
+func TestJSONPResponse(t *testing.T) {
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	err := c.JSONP(200, "callback", map[string]int{"id": 1})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `callback({"id":1});`, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c = h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.JSONP(200, "alert(1)//", 1)
+
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestJSONPrettyResponse(t *testing.T) {
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.JSONPretty(200, map[string]int{"id": 1}, "  ")
+
+	assert.Equal(t, "{\n  \"id\": 1\n}", w.Body.String())
+}
+
+func TestBlobStreamAndNoContent(t *testing.T) {
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.Stream(200, "text/csv", strings.NewReader("a,b\n"))
+
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "a,b\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	c = h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.NoContent(204)
+
+	assert.Equal(t, 204, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestNegotiate(t *testing.T) {
+	h := New()
+
+	negotiate := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+
+		h.NewContext(w, r).Negotiate(200,
+			Offer{MIMEApplicationJSON, "husky"},
+			Offer{MIMEApplicationXML, "husky"},
+			Offer{MIMETextPlain, "husky"},
+		)
+		return w
+	}
+
+	assert.Equal(t, "application/json", negotiate("").Header().Get("Content-Type"))
+	assert.Equal(t, "application/xml;charset=utf-8", negotiate("text/html;q=0.9, application/xml").Header().Get("Content-Type"))
+	assert.Equal(t, "text/plain;charset=utf-8", negotiate("application/json;q=0.5, text/*").Header().Get("Content-Type"))
+	assert.Equal(t, "application/json", negotiate("*/*").Header().Get("Content-Type"))
+	assert.Equal(t, 406, negotiate("image/png").Code)
+	assert.Equal(t, 406, negotiate("application/json;q=0, application/xml;q=0, text/plain;q=0").Code)
+	assert.Equal(t, "Accept", negotiate("image/png").Header().Get("Vary"))
+	assert.Equal(t, "Accept", negotiate("*/*").Header().Get("Vary"))
+}
+
+func TestNegotiateEscapesHTML(t *testing.T) {
+	h := New()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	h.NewContext(w, r).Negotiate(200, Offer{MIMETextHTML, "<script>alert(1)</script>"})
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt;", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.NewContext(w, r).Negotiate(200, Offer{MIMETextHTML, template.HTML("<b>husky</b>")})
+	assert.Equal(t, "<b>husky</b>", w.Body.String())
+}
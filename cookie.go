@@ -0,0 +1,144 @@
+package husky
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCookie is returned when a cookie value can not be verified
+var ErrInvalidCookie = errors.New("husky: invalid cookie value")
+
+// CookieCodec signs or encrypts cookie values so they can hold small tamper
+// proof state client side. The first key is used to encode, all keys are
+// tried when decoding which allows keys to be rotated.
+type CookieCodec struct {
+	MaxAge  time.Duration // values older than MaxAge are rejected, 0 disables the check
+	keys    [][]byte
+	aeads   []cipher.AEAD
+	encrypt bool
+}
+
+// NewSignedCookieCodec creates a codec that signs values with HMAC-SHA256,
+// signed values can be read by the client but not modified
+func NewSignedCookieCodec(keys ...[]byte) (*CookieCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("husky: cookie codec requires at least one key")
+	}
+
+	return &CookieCodec{keys: keys}, nil
+}
+
+// NewEncryptedCookieCodec creates a codec that encrypts values with AES-GCM,
+// keys must be 16, 24 or 32 bytes long
+func NewEncryptedCookieCodec(keys ...[]byte) (*CookieCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("husky: cookie codec requires at least one key")
+	}
+
+	codec := &CookieCodec{keys: keys, encrypt: true}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		codec.aeads = append(codec.aeads, aead)
+	}
+
+	return codec, nil
+}
+
+// Encode signs or encrypts the value of the named cookie
+func (codec *CookieCodec) Encode(name string, value string) (string, error) {
+	payload := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(payload, uint64(time.Now().Unix()))
+	payload = append(payload, value...)
+
+	var encoded []byte
+	if codec.encrypt {
+		aead := codec.aeads[0]
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+
+		encoded = aead.Seal(nonce, nonce, payload, []byte(name))
+	} else {
+		encoded = append(payload, sign(codec.keys[0], name, payload)...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// Decode verifies and returns the value of the named cookie
+func (codec *CookieCodec) Decode(name string, encoded string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	payload, ok := codec.open(name, data)
+	if !ok || len(payload) < 8 {
+		return "", ErrInvalidCookie
+	}
+
+	created := time.Unix(int64(binary.BigEndian.Uint64(payload[:8])), 0)
+	if codec.MaxAge > 0 && time.Since(created) > codec.MaxAge {
+		return "", ErrInvalidCookie
+	}
+
+	return string(payload[8:]), nil
+}
+
+// open tries every key until one verifies the data
+func (codec *CookieCodec) open(name string, data []byte) ([]byte, bool) {
+	if codec.encrypt {
+		for _, aead := range codec.aeads {
+			if len(data) < aead.NonceSize() {
+				return nil, false
+			}
+
+			nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+			if payload, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+				return payload, true
+			}
+		}
+
+		return nil, false
+	}
+
+	if len(data) < sha256.Size {
+		return nil, false
+	}
+
+	payload, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	for _, key := range codec.keys {
+		if hmac.Equal(mac, sign(key, name, payload)) {
+			return payload, true
+		}
+	}
+
+	return nil, false
+}
+
+// sign returns the HMAC-SHA256 of the cookie name and payload
+func sign(key []byte, name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,67 @@
+package husky
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedCookieCodec(t *testing.T) {
+	codec, _ := NewSignedCookieCodec([]byte("secret"))
+
+	encoded, err := codec.Encode("session", "user=1")
+	assert.NoError(t, err)
+
+	value, err := codec.Decode("session", encoded)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user=1", value)
+	}
+
+	// values are bound to the cookie name
+	_, err = codec.Decode("other", encoded)
+	assert.Equal(t, ErrInvalidCookie, err)
+
+	_, err = codec.Decode("session", encoded[:len(encoded)-2]+"AA")
+	assert.Equal(t, ErrInvalidCookie, err)
+}
+
+func TestEncryptedCookieCodec(t *testing.T) {
+	codec, err := NewEncryptedCookieCodec([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+
+	encoded, _ := codec.Encode("session", "user=1")
+	assert.NotContains(t, encoded, "user")
+
+	value, err := codec.Decode("session", encoded)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user=1", value)
+	}
+
+	_, err = NewEncryptedCookieCodec([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestCookieCodecKeyRotation(t *testing.T) {
+	old, _ := NewEncryptedCookieCodec([]byte("0123456789abcdef"))
+	encoded, _ := old.Encode("session", "user=1")
+
+	rotated, _ := NewEncryptedCookieCodec([]byte("fedcba9876543210"), []byte("0123456789abcdef"))
+	value, err := rotated.Decode("session", encoded)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user=1", value)
+	}
+}
+
+func TestCookieCodecMaxAge(t *testing.T) {
+	codec, _ := NewSignedCookieCodec([]byte("secret"))
+	encoded, _ := codec.Encode("session", "user=1")
+
+	codec.MaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	_, err := codec.Decode("session", encoded)
+
+	assert.Equal(t, ErrInvalidCookie, err)
+}
@@ -0,0 +1,203 @@
+package husky
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugProfileDuration is the default duration of CPU profiles and traces
+const DebugProfileDuration = 30 * time.Second
+
+// Debug adds runtime diagnostics under prefix when DEBUG_ENDPOINTS is true in
+// the config, every endpoint runs behind guard, e.g. middleware.BasicAuth
+//
+//	GET prefix/pprof               profiles available with their counts
+//	GET prefix/pprof/profile       CPU profile, ?seconds=30
+//	GET prefix/pprof/trace         execution trace, ?seconds=30
+//	GET prefix/pprof/cmdline       command line of the process
+//	GET prefix/pprof/:name         named profile, e.g. heap, ?debug=1 for text
+//	GET prefix/goroutines          stack dump of all goroutines
+//	GET prefix/vars                expvar variables
+//	GET prefix/build               build info of the binary
+//	GET prefix/routes              registered routes
+//
+// Panics if guard is nil. Long profiles need a WRITE_TIMEOUT above their duration.
+func (husky *Husky) Debug(prefix string, guard MiddlewareHandler, middleware ...MiddlewareHandler) {
+	if guard == nil {
+		panic("husky: debug endpoints require a guard middleware")
+	}
+
+	if enabled, _ := strconv.ParseBool(husky.Config.Load()["DEBUG_ENDPOINTS"]); !enabled {
+		return
+	}
+
+	log.Printf("Debug endpoints enabled at %s", prefix)
+	husky.debug(prefix, guard, middleware)
+}
+
+// debug adds the debug routes, the guard wraps all other middleware
+func (husky *Husky) debug(prefix string, guard MiddlewareHandler, middleware []MiddlewareHandler) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	middleware = append(append([]MiddlewareHandler(nil), middleware...), guard)
+
+	routes := map[string]Handler{
+		"/pprof":         debugProfiles,
+		"/pprof/profile": debugCPUProfile,
+		"/pprof/trace":   debugTrace,
+		"/pprof/cmdline": debugCmdline,
+		"/pprof/:name":   debugProfile,
+		"/goroutines":    debugGoroutines,
+		"/vars":          WrapHandler(expvar.Handler()),
+		"/build":         debugBuildInfo,
+		"/routes": func(ctx *CTX) error {
+			return ctx.JSON(200, husky.debugRoutes())
+		},
+	}
+
+	// internal endpoints are left out of the OpenAPI document
+	for endpoint, handler := range routes {
+		husky.add("GET", prefix+endpoint, handler, middleware).Hidden()
+	}
+}
+
+// debugRoute describes a registered route
+type debugRoute struct {
+	Method     string `json:"method"`
+	Endpoint   string `json:"endpoint"`
+	Middleware int    `json:"middleware"`
+}
+
+func (husky *Husky) debugRoutes() []debugRoute {
+	routes := []debugRoute{}
+	for method, endpoints := range husky.Router.Routes {
+		for _, route := range endpoints {
+			routes = append(routes, debugRoute{
+				Method:     method,
+				Endpoint:   route.Endpoint,
+				Middleware: len(route.Middleware),
+			})
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Endpoint != routes[j].Endpoint {
+			return routes[i].Endpoint < routes[j].Endpoint
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+func debugProfiles(ctx *CTX) error {
+	profiles := make(map[string]int)
+	for _, profile := range pprof.Profiles() {
+		profiles[profile.Name()] = profile.Count()
+	}
+
+	return ctx.JSON(200, profiles)
+}
+
+func debugProfile(ctx *CTX) error {
+	name := ctx.GetParam("name")
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		return ctx.JSON(404, "Unknown profile: "+name)
+	}
+
+	if name == "heap" && ctx.GetParam("gc") == "1" {
+		runtime.GC()
+	}
+
+	level, _ := strconv.Atoi(ctx.GetParam("debug"))
+	debugHeaders(ctx, name, level)
+
+	return profile.WriteTo(ctx.Response, level)
+}
+
+func debugGoroutines(ctx *CTX) error {
+	debugHeaders(ctx, "goroutine", 2)
+	return pprof.Lookup("goroutine").WriteTo(ctx.Response, 2)
+}
+
+func debugCPUProfile(ctx *CTX) error {
+	debugHeaders(ctx, "profile", 0)
+
+	if err := pprof.StartCPUProfile(ctx.Response); err != nil {
+		ctx.Response.Header().Del("Content-Disposition")
+		return ctx.JSON(500, "Could not start CPU profile: "+err.Error())
+	}
+
+	debugSleep(ctx)
+	pprof.StopCPUProfile()
+
+	return nil
+}
+
+func debugTrace(ctx *CTX) error {
+	debugHeaders(ctx, "trace", 0)
+
+	if err := rtrace.Start(ctx.Response); err != nil {
+		ctx.Response.Header().Del("Content-Disposition")
+		return ctx.JSON(500, "Could not start trace: "+err.Error())
+	}
+
+	debugSleep(ctx)
+	rtrace.Stop()
+
+	return nil
+}
+
+func debugCmdline(ctx *CTX) error {
+	return ctx.String(200, strings.Join(os.Args, "\x00"))
+}
+
+func debugBuildInfo(ctx *CTX) error {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ctx.JSON(404, "Build info not available")
+	}
+
+	return ctx.JSON(200, info)
+}
+
+// debugHeaders sets the headers of a profile, binary profiles are downloaded
+func debugHeaders(ctx *CTX, name string, level int) {
+	header := ctx.Response.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	if level > 0 {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		return
+	}
+
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+}
+
+// debugSleep waits for the seconds param or until the client disconnects
+func debugSleep(ctx *CTX) {
+	duration := DebugProfileDuration
+	if seconds, err := strconv.Atoi(ctx.GetParam("seconds")); err == nil && seconds > 0 {
+		duration = time.Duration(seconds) * time.Second
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Request.Context().Done():
+	}
+}
@@ -0,0 +1,112 @@
+package husky
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// debugGuard only lets requests with the debug token through
+func debugGuard(next Handler) Handler {
+	return func(ctx *CTX) error {
+		if ctx.GetHeader("Authorization") != "Bearer debug" {
+			return ctx.JSON(401, "Unauthorized")
+		}
+
+		return next(ctx)
+	}
+}
+
+func debugRequest(h *Husky, target string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", target, nil)
+	r.Header.Set("Authorization", "Bearer debug")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestDebugDisabled(t *testing.T) {
+	h := New()
+
+	assert.Panics(t, func() {
+		h.Debug("/debug", nil)
+	})
+
+	// DEBUG_ENDPOINTS is false in .env
+	h.Debug("/debug", debugGuard)
+	assert.Empty(t, h.Router.Routes["GET"])
+}
+
+func TestDebugGuard(t *testing.T) {
+	h := New()
+	h.debug("/debug", debugGuard, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/heap", nil))
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestDebugEndpoints(t *testing.T) {
+	h := New()
+	h.GET("/users/:id", func(ctx *CTX) error {
+		return ctx.NoContent(204)
+	})
+	h.debug("/debug/", debugGuard, nil)
+
+	w := debugRequest(h, "/debug/routes")
+	assert.Equal(t, 200, w.Code)
+
+	var routes []debugRoute
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &routes))
+	assert.Contains(t, routes, debugRoute{Method: "GET", Endpoint: "/users/:id"})
+	assert.Contains(t, routes, debugRoute{Method: "GET", Endpoint: "/debug/routes", Middleware: 1})
+
+	w = debugRequest(h, "/debug/pprof")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"goroutine"`)
+
+	w = debugRequest(h, "/debug/pprof/heap?debug=1")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "heap profile")
+
+	w = debugRequest(h, "/debug/pprof/allocs")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `attachment; filename="allocs"`, w.Header().Get("Content-Disposition"))
+	assert.NotEmpty(t, w.Body.Bytes())
+
+	w = debugRequest(h, "/debug/pprof/unknown")
+	assert.Equal(t, 404, w.Code)
+
+	w = debugRequest(h, "/debug/goroutines")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "TestDebugEndpoints")
+
+	w = debugRequest(h, "/debug/vars")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"memstats"`)
+
+	w = debugRequest(h, "/debug/build")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"GoVersion"`)
+
+	// debug routes are left out of the OpenAPI document
+	assert.Len(t, h.OpenAPI(OpenAPIInfo{}).Paths, 1)
+}
+
+func TestDebugCPUProfile(t *testing.T) {
+	h := New()
+	h.debug("/debug", debugGuard, nil)
+
+	w := debugRequest(h, "/debug/pprof/profile?seconds=1")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Body.Bytes())
+}
@@ -8,36 +8,40 @@ type Group struct {
 }
 
 // GET adds a HTTP Get method to the group
-func (g *Group) GET(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	g.add("GET", endpoint, handler, middleware)
+func (g *Group) GET(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return g.add("GET", endpoint, handler, middleware)
 }
 
 // POST adds a HTTP POST method to the group
-func (g *Group) POST(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	g.add("POST", endpoint, handler, middleware)
+func (g *Group) POST(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return g.add("POST", endpoint, handler, middleware)
 }
 
 // PATCH adds a HTTP PATCH method to the group
-func (g *Group) PATCH(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	g.add("PATCH", endpoint, handler, middleware)
+func (g *Group) PATCH(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return g.add("PATCH", endpoint, handler, middleware)
 }
 
 // PUT adds a HTTP PUT method to the group
-func (g *Group) PUT(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	g.add("PUT", endpoint, handler, middleware)
+func (g *Group) PUT(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return g.add("PUT", endpoint, handler, middleware)
 }
 
 // DELETE adds a HTTP DELETE method to the group
-func (g *Group) DELETE(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	g.add("DELETE", endpoint, handler, middleware)
+func (g *Group) DELETE(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return g.add("DELETE", endpoint, handler, middleware)
 }
 
 // Middleware adds a middleware handler to be executed after route is found
-// but before the handler is executed
+// but before the handler is executed, applies to routes added afterwards
 func (g *Group) Middleware(m MiddlewareHandler) {
 	g.MiddlewareHandlers = append(g.MiddlewareHandlers, m)
 }
 
-func (g *Group) add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) {
-	g.Husky.add(verb, g.Prefix+endpoint, handler, middleware)
+func (g *Group) add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) *RouteDoc {
+	handlers := make([]MiddlewareHandler, 0, len(g.MiddlewareHandlers)+len(middleware))
+	handlers = append(handlers, g.MiddlewareHandlers...)
+	handlers = append(handlers, middleware...)
+
+	return g.Husky.add(verb, g.Prefix+endpoint, handler, handlers)
 }
@@ -97,3 +97,14 @@ func TestAddGroupMiddleware(t *testing.T) {
 
 	assert.True(t, len(g.MiddlewareHandlers) == 2)
 }
+
+func TestGroupMiddlewareIsAppliedToRoutes(t *testing.T) {
+	h := New()
+
+	g := h.Group("/group", middlware)
+	g.GET("/path", handler, middlware)
+
+	found := h.Router.GetRoutes("GET")
+
+	assert.Equal(t, 2, len(found["GET/group/path"].Middleware))
+}
@@ -0,0 +1,257 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCheckTimeout is reported for checks exceeding their timeout
+var ErrCheckTimeout = errors.New("husky: health check timed out")
+
+// DefaultCheckTimeout is the timeout of checks registered without one
+var DefaultCheckTimeout = 5 * time.Second
+
+// DefaultCheckCacheTTL is how long check results are reused between probes
+var DefaultCheckCacheTTL = time.Second
+
+// CheckFunc reports the health of a component, a nil error is healthy
+type CheckFunc func(ctx context.Context) error
+
+// Health is a registry of liveness and readiness checks served at /livez
+// and /readyz, or LIVENESS_PATH and READINESS_PATH of the config
+type Health struct {
+	CacheTTL time.Duration // results are reused for this long, 0 disables caching
+
+	mu     sync.Mutex
+	checks map[string]*check
+	husky  *Husky
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	timeout  time.Duration
+	liveness bool
+
+	mu       sync.Mutex
+	result   CheckResult
+	checked  time.Time
+	inflight chan struct{}
+}
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReport is the JSON body of the health endpoints
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Health returns the check registry of the service, the first call registers
+// the liveness and readiness endpoints
+func (husky *Husky) Health() *Health {
+	config := husky.Config.Load()
+
+	livez, readyz := config["LIVENESS_PATH"], config["READINESS_PATH"]
+	if livez == "" {
+		livez = "/livez"
+	}
+	if readyz == "" {
+		readyz = "/readyz"
+	}
+
+	return husky.healthAt(livez, readyz)
+}
+
+// healthAt returns the check registry, registering its endpoints on first use
+func (husky *Husky) healthAt(livez string, readyz string) *Health {
+	husky.mu.Lock()
+	defer husky.mu.Unlock()
+
+	if husky.health != nil {
+		return husky.health
+	}
+
+	health := &Health{
+		CacheTTL: DefaultCheckCacheTTL,
+		checks:   make(map[string]*check),
+		husky:    husky,
+	}
+
+	husky.add("GET", livez, func(ctx *CTX) error {
+		return health.serve(ctx, true)
+	}, nil)
+
+	husky.add("GET", readyz, func(ctx *CTX) error {
+		return health.serve(ctx, false)
+	}, nil)
+
+	husky.health = health
+	return health
+}
+
+// Liveness adds a check to both endpoints, only use it for failures a
+// restart of the process fixes, e.g. a deadlocked worker
+func (health *Health) Liveness(name string, timeout time.Duration, fn CheckFunc) {
+	health.add(name, timeout, fn, true)
+}
+
+// Readiness adds a check to the readiness endpoint, e.g. the database connection
+func (health *Health) Readiness(name string, timeout time.Duration, fn CheckFunc) {
+	health.add(name, timeout, fn, false)
+}
+
+func (health *Health) add(name string, timeout time.Duration, fn CheckFunc, liveness bool) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	health.checks[name] = &check{name: name, fn: fn, timeout: timeout, liveness: liveness}
+}
+
+// Check runs the liveness checks, or all checks for readiness, concurrently
+// and reports whether all of them passed. Readiness fails while the service
+// is draining after Shutdown.
+func (health *Health) Check(ctx context.Context, liveness bool) (HealthReport, bool) {
+	health.mu.Lock()
+	var checks []*check
+	for _, c := range health.checks {
+		if c.liveness || !liveness {
+			checks = append(checks, c)
+		}
+	}
+	health.mu.Unlock()
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, health.CacheTTL)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]CheckResult)}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "failing"
+		}
+	}
+
+	if !liveness && health.husky != nil && health.husky.Draining() {
+		report.Status = "draining"
+	}
+
+	return report, report.Status == "ok"
+}
+
+// serve writes the report with a 200 when healthy and a 503 otherwise
+func (health *Health) serve(ctx *CTX, liveness bool) error {
+	report, ok := health.Check(ctx.Context(), liveness)
+
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+
+	ctx.Response.Header().Set("Cache-Control", "no-store")
+	return ctx.JSON(code, report)
+}
+
+// run returns the cached result or runs the check, concurrent probes wait
+// for the running check instead of starting another one
+func (c *check) run(ctx context.Context, ttl time.Duration) CheckResult {
+	c.mu.Lock()
+	if ttl > 0 && !c.checked.IsZero() && time.Since(c.checked) < ttl {
+		result := c.result
+		c.mu.Unlock()
+		return result
+	}
+
+	if c.inflight != nil {
+		inflight := c.inflight
+		c.mu.Unlock()
+
+		select {
+		case <-inflight:
+		case <-ctx.Done():
+			return CheckResult{Status: "failing", Error: ctx.Err().Error()}
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.result
+	}
+
+	inflight := make(chan struct{})
+	c.inflight = inflight
+	c.mu.Unlock()
+
+	// a probe disconnecting must not cache a failure for the other probes
+	result := c.execute(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	c.result = result
+	c.checked = time.Now()
+	c.inflight = nil
+	c.mu.Unlock()
+	close(inflight)
+
+	return result
+}
+
+// execute runs the check with its timeout, a check ignoring its context is
+// reported as timed out and left running in the background
+func (c *check) execute(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrCheckTimeout
+	}
+
+	result := CheckResult{Status: "ok", Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = "failing"
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Draining reports if Shutdown was called
+func (husky *Husky) Draining() bool {
+	husky.mu.Lock()
+	defer husky.mu.Unlock()
+
+	return husky.draining
+}
@@ -0,0 +1,136 @@
+package husky
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(h *Husky, endpoint string) (int, HealthReport) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", endpoint, nil))
+
+	var report HealthReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+
+	return w.Code, report
+}
+
+func TestHealthLivenessAndReadiness(t *testing.T) {
+	h := New()
+
+	health := h.Health()
+	assert.True(t, health == h.Health())
+
+	health.Liveness("workers", 0, func(ctx context.Context) error {
+		return nil
+	})
+	health.Readiness("database", 0, func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	code, report := probe(h, "/livez")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "ok", report.Status)
+	assert.Len(t, report.Checks, 1)
+
+	code, report = probe(h, "/readyz")
+	assert.Equal(t, 503, code)
+	assert.Equal(t, "failing", report.Status)
+	assert.Equal(t, "ok", report.Checks["workers"].Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	h := New()
+
+	h.Health().Readiness("slow", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	code, report := probe(h, "/readyz")
+	assert.Equal(t, 503, code)
+	assert.Equal(t, ErrCheckTimeout.Error(), report.Checks["slow"].Error)
+}
+
+func TestHealthCachesResults(t *testing.T) {
+	h := New()
+
+	var runs int32
+	health := h.Health()
+	health.Readiness("counted", 0, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	probe(h, "/readyz")
+	probe(h, "/readyz")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	health.CacheTTL = 0
+	probe(h, "/readyz")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestShutdownFailsReadiness(t *testing.T) {
+	h := New()
+	h.Health()
+
+	code, _ := probe(h, "/readyz")
+	assert.Equal(t, 200, code)
+
+	assert.NoError(t, h.Shutdown(context.Background()))
+	assert.True(t, h.Draining())
+
+	code, report := probe(h, "/readyz")
+	assert.Equal(t, 503, code)
+	assert.Equal(t, "draining", report.Status)
+
+	code, _ = probe(h, "/livez")
+	assert.Equal(t, 200, code)
+}
+
+func TestShutdownDelayServesDraining(t *testing.T) {
+	h := New()
+	h.Health()
+
+	done := make(chan error)
+	go func() {
+		done <- h.shutdown(context.Background(), 100*time.Millisecond)
+	}()
+
+	// probes during the delay see the service draining
+	time.Sleep(20 * time.Millisecond)
+	code, report := probe(h, "/readyz")
+	assert.Equal(t, 503, code)
+	assert.Equal(t, "draining", report.Status)
+
+	select {
+	case <-done:
+		t.Fatal("shutdown returned before the delay")
+	default:
+	}
+
+	assert.NoError(t, <-done)
+}
+
+func TestHealthPaths(t *testing.T) {
+	h := New()
+	h.healthAt("/health/live", "/health/ready")
+
+	code, _ := probe(h, "/health/live")
+	assert.Equal(t, 200, code)
+
+	code, _ = probe(h, "/health/ready")
+	assert.Equal(t, 200, code)
+
+	code, _ = probe(h, "/livez")
+	assert.Equal(t, 404, code)
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Husky struct holds router and context for framework
@@ -14,8 +16,24 @@ type Husky struct {
 	BeforeMiddleware []MiddlewareHandler
 	Config           Configuration
 	Context          *CTX
+	ErrorHandler     ErrorHandler
+	Metrics          *Metrics
 	Middleware       []MiddlewareHandler
+	Multipart        MultipartConfig
+	NotFound         Handler
+	Renderer         Renderer
 	Router           *Router
+
+	mu           sync.Mutex
+	container    *container
+	draining     bool
+	health       *Health
+	httpServer   *http.Server
+	running      bool
+	shutdownDone chan struct{}
+	started      int
+	startHooks   []Hook
+	stopHooks    []stopHook
 }
 
 // Handler basic function to router handlers
@@ -24,6 +42,21 @@ type Handler func(*CTX) error
 // MiddlewareHandler defines a function to process middleware
 type MiddlewareHandler func(Handler) Handler
 
+// ErrorHandler handles errors returned by route handlers
+type ErrorHandler func(err error, ctx *CTX)
+
+// DefaultErrorHandler logs the error and sends a 500 response unless a
+// response was already written
+func DefaultErrorHandler(err error, ctx *CTX) {
+	log.Printf("Handler error: %s %s: %s", ctx.Request.Method, ctx.Request.URL.Path, err)
+
+	if ctx.Response.Committed {
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+}
+
 // NotFoundHandler default 404 handler for not found routes
 func NotFoundHandler(ctx *CTX) (err error) {
 	b, _ := json.Marshal("Not Found")
@@ -38,7 +71,11 @@ func NotFoundHandler(ctx *CTX) (err error) {
 // New creates a new service
 func New() (husky *Husky) {
 	return &Husky{
-		Router: new(Router),
+		ErrorHandler: DefaultErrorHandler,
+		Metrics:      NewMetrics(),
+		Multipart:    DefaultMultipartConfig,
+		NotFound:     NotFoundHandler,
+		Router:       new(Router),
 	}
 }
 
@@ -69,33 +106,33 @@ func (husky *Husky) Middlware(middleware MiddlewareHandler) {
 }
 
 // DELETE adds a HTTP DELETE route to router
-func (husky *Husky) DELETE(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("DELETE", endpoint, handler, middleware)
+func (husky *Husky) DELETE(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("DELETE", endpoint, handler, middleware)
 }
 
 // GET adds a HTTP GET route to router
-func (husky *Husky) GET(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("GET", endpoint, handler, middleware)
+func (husky *Husky) GET(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("GET", endpoint, handler, middleware)
 }
 
 // OPTIONS adds a HTTP OPTIONS route to router
-func (husky *Husky) OPTIONS(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("OPTIONS", endpoint, handler, middleware)
+func (husky *Husky) OPTIONS(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("OPTIONS", endpoint, handler, middleware)
 }
 
 // PATCH adds a HTTP PATCH route to router
-func (husky *Husky) PATCH(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("PATCH", endpoint, handler, middleware)
+func (husky *Husky) PATCH(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("PATCH", endpoint, handler, middleware)
 }
 
 // POST adds a HTTP POST route to router
-func (husky *Husky) POST(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("POST", endpoint, handler, middleware)
+func (husky *Husky) POST(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("POST", endpoint, handler, middleware)
 }
 
 // PUT adds a HTTP PUT route to router
-func (husky *Husky) PUT(endpoint string, handler Handler, middleware ...MiddlewareHandler) {
-	husky.add("PUT", endpoint, handler, middleware)
+func (husky *Husky) PUT(endpoint string, handler Handler, middleware ...MiddlewareHandler) *RouteDoc {
+	return husky.add("PUT", endpoint, handler, middleware)
 }
 
 // Group creates a route group with a common prefix
@@ -105,14 +142,6 @@ func (husky *Husky) Group(prefix string, middleware ...MiddlewareHandler) *Group
 	return group
 }
 
-// Start initates the framework to start listening for requests
-func (husky *Husky) Start() {
-	server := husky.server()
-	if err := server.ListenAndServe(); err != nil {
-		log.Printf("Server error: %s", err)
-	}
-}
-
 func (husky *Husky) server() *http.Server {
 	config := husky.Config.Load()
 
@@ -120,8 +149,12 @@ func (husky *Husky) server() *http.Server {
 	port := config["PORT"]
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: husky,
+		Addr:              ":8080",
+		Handler:           husky,
+		ReadTimeout:       duration(config, "READ_TIMEOUT", 0),
+		ReadHeaderTimeout: duration(config, "READ_HEADER_TIMEOUT", 10*time.Second),
+		WriteTimeout:      duration(config, "WRITE_TIMEOUT", 0),
+		IdleTimeout:       duration(config, "IDLE_TIMEOUT", 120*time.Second),
 	}
 
 	fmt.Println("==> Running " + name + " on port: " + port)
@@ -134,12 +167,16 @@ func (husky *Husky) NewContext(w http.ResponseWriter, r *http.Request) *CTX {
 	return &CTX{
 		Request:  r,
 		Response: NewResponse(w),
+		husky:    husky,
+		cleanups: &cleanupQueue{},
 	}
 }
 
 func (husky *Husky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// create context
-	husky.Context = husky.NewContext(w, r)
+	ctx := husky.NewContext(w, r)
+	husky.Context = ctx
+	defer ctx.Cleanup()
 	var handler Handler
 
 	// execute BeforeMiddleware
@@ -150,29 +187,34 @@ func (husky *Husky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// execute handler
-	if found, route := husky.Router.FindRoute(husky.Context); found {
-		handler := func(*CTX) error {
-			handler := route.Handler
-
-			// execute middleware chain
-			if len(husky.Middleware) > 0 {
-				for i := 0; i < len(husky.Middleware); i++ {
-					handler = husky.Middleware[i](handler)
-				}
-			}
-
-			// execute route
-			if err := handler(husky.Context); err != nil {
-				panic(err)
-			}
-
-			return nil
+	if found, route := husky.Router.FindRoute(ctx); found {
+		ctx.path = route.Endpoint
+		handler := route.Handler
+
+		// execute route middleware chain
+		for i := 0; i < len(route.Middleware); i++ {
+			handler = route.Middleware[i](handler)
+		}
+
+		// execute middleware chain, wrapping the route middleware
+		for i := 0; i < len(husky.Middleware); i++ {
+			handler = husky.Middleware[i](handler)
 		}
 
-		handler(husky.Context)
+		// execute route
+		if err := handler(ctx); err != nil {
+			ctx.Error(err)
+		}
 	} else {
 		// route was not found
-		NotFoundHandler(husky.Context)
+		notFound := husky.NotFound
+		if notFound == nil {
+			notFound = NotFoundHandler
+		}
+
+		if err := notFound(ctx); err != nil {
+			ctx.Error(err)
+		}
 	}
 
 	// execute AfterMiddleware
@@ -185,7 +227,7 @@ func (husky *Husky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
-func (husky *Husky) add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) {
+func (husky *Husky) add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) *RouteDoc {
 	path := strings.Split(endpoint, "?")
-	husky.Router.Add(verb, path[0], handler, middleware)
+	return husky.Router.Add(verb, path[0], handler, middleware)
 }
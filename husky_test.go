@@ -1,11 +1,13 @@
 package husky
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -77,6 +79,32 @@ func TestAddMiddleware(t *testing.T) {
 	assert.True(t, len(h.Middleware) == 1)
 }
 
+func TestRouteMiddlewareIsExecuted(t *testing.T) {
+	h := New()
+
+	var order []string
+	mw := func(name string) MiddlewareHandler {
+		return func(next Handler) Handler {
+			return func(c *CTX) error {
+				order = append(order, name)
+				return next(c)
+			}
+		}
+	}
+
+	h.Middlware(mw("global"))
+	h.GET("/path", func(c *CTX) error {
+		order = append(order, "handler")
+		return c.JSON(200, "This is a test")
+	}, mw("route"))
+
+	r, _ := http.NewRequest("GET", "/path", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, []string{"global", "route", "handler"}, order)
+}
+
 func TestCreateGroup(t *testing.T) {
 	h := New()
 
@@ -220,6 +248,28 @@ func TestNotFoundHandler(t *testing.T) {
 	}
 }
 
+func TestCustomNotFound(t *testing.T) {
+	h := New()
+	h.NotFound = func(c *CTX) error {
+		return c.JSON(404, "No route for "+c.Request.URL.Path)
+	}
+
+	r, _ := http.NewRequest("GET", "/blah", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "\"No route for /blah\"", w.Body.String())
+
+	// a nil NotFound falls back to NotFoundHandler
+	h.NotFound = nil
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "\"Not Found\"", w.Body.String())
+}
+
 func TestNewServerReturnsHTTPServer(t *testing.T) {
 	h := New()
 	server := h.server()
@@ -227,9 +277,55 @@ func TestNewServerReturnsHTTPServer(t *testing.T) {
 	assert.True(t, reflect.TypeOf(server).String() == "*http.Server")
 }
 
+func TestNewServerTimeouts(t *testing.T) {
+	h := New()
+	server := h.server()
+
+	assert.Equal(t, 30*time.Second, server.ReadTimeout)
+	assert.Equal(t, 10*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 120*time.Second, server.IdleTimeout)
+}
+
 func TestNewServerRunsOnCorrectPort(t *testing.T) {
 	h := New()
 	server := h.server()
 
 	assert.Equal(t, ":8080", server.Addr)
 }
+
+func TestHandlerErrorsUseErrorHandler(t *testing.T) {
+	h := New()
+
+	h.GET("/fail", func(ctx *CTX) error {
+		return errors.New("failed")
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, `"Internal Server Error"`, w.Body.String())
+
+	var handled []error
+	h.ErrorHandler = func(err error, ctx *CTX) {
+		handled = append(handled, err)
+		ctx.JSON(503, err.Error())
+	}
+
+	// errors handled by middleware are not handled again
+	h.GET("/handled", func(ctx *CTX) error {
+		return errors.New("handled")
+	}, func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			err := next(ctx)
+			ctx.Error(err)
+			return err
+		}
+	})
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/handled", nil))
+
+	assert.Equal(t, 503, w.Code)
+	assert.Len(t, handled, 1)
+}
@@ -0,0 +1,290 @@
+package huskytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UpdateEnv is the environment variable which rewrites golden files when set
+// to a non empty value, e.g. HUSKYTEST_UPDATE=1 go test ./...
+const UpdateEnv = "HUSKYTEST_UPDATE"
+
+// GoldenDir is the directory of golden files, relative to the test package
+var GoldenDir = "testdata"
+
+// TestingT is the part of *testing.T used by the assertions
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Expectation asserts on a recorded response, failed assertions are reported
+// with t.Errorf so every failure of a chain is listed
+type Expectation struct {
+	t        TestingT
+	response *Response
+}
+
+func (e *Expectation) helper() {
+	if h, ok := e.t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+}
+
+// Response returns the recorded response
+func (e *Expectation) Response() *Response {
+	return e.response
+}
+
+// Status asserts the status code
+func (e *Expectation) Status(code int) *Expectation {
+	e.helper()
+
+	if actual := e.response.Recorder.Code; actual != code {
+		e.t.Errorf("huskytest: expected status %d, got %d", code, actual)
+	}
+
+	return e
+}
+
+// Header asserts the value of a response header
+func (e *Expectation) Header(key string, value string) *Expectation {
+	e.helper()
+
+	if actual := e.response.Recorder.Header().Get(key); actual != value {
+		e.t.Errorf("huskytest: expected header %s %q, got %q", key, value, actual)
+	}
+
+	return e
+}
+
+// Body asserts the response body
+func (e *Expectation) Body(body string) *Expectation {
+	e.helper()
+
+	if actual := e.response.Recorder.Body.String(); actual != body {
+		e.t.Errorf("huskytest: expected body %q, got %q", body, actual)
+	}
+
+	return e
+}
+
+// BodyContains asserts the response body contains s
+func (e *Expectation) BodyContains(s string) *Expectation {
+	e.helper()
+
+	if actual := e.response.Recorder.Body.String(); !strings.Contains(actual, s) {
+		e.t.Errorf("huskytest: expected body to contain %q, got %q", s, actual)
+	}
+
+	return e
+}
+
+// JSON asserts the body is JSON equal to expected, regardless of formatting
+// and key order
+func (e *Expectation) JSON(expected interface{}) *Expectation {
+	e.helper()
+
+	actual, err := e.decode()
+	if err != nil {
+		e.t.Errorf("huskytest: invalid JSON body: %s", err)
+		return e
+	}
+
+	if !jsonEqual(expected, actual) {
+		e.t.Errorf("huskytest: expected JSON %s, got %s", encode(expected), encode(actual))
+	}
+
+	return e
+}
+
+// JSONPath asserts the value at path equals expected, paths support fields
+// and array indexes, e.g. "$.users[0].name"
+func (e *Expectation) JSONPath(path string, expected interface{}) *Expectation {
+	e.helper()
+
+	body, err := e.decode()
+	if err != nil {
+		e.t.Errorf("huskytest: invalid JSON body: %s", err)
+		return e
+	}
+
+	actual, err := lookup(body, path)
+	if err != nil {
+		e.t.Errorf("huskytest: %s", err)
+		return e
+	}
+
+	if !jsonEqual(expected, actual) {
+		e.t.Errorf("huskytest: expected %s to be %s, got %s", path, encode(expected), encode(actual))
+	}
+
+	return e
+}
+
+// Golden compares a snapshot of the status, headers and body with the golden
+// file GoldenDir/name.golden, the file is written when UpdateEnv is set
+func (e *Expectation) Golden(name string) *Expectation {
+	e.helper()
+
+	snapshot := e.snapshot()
+	file := filepath.Join(GoldenDir, name+".golden")
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			e.t.Errorf("huskytest: %s", err)
+			return e
+		}
+
+		if err := os.WriteFile(file, snapshot, 0644); err != nil {
+			e.t.Errorf("huskytest: %s", err)
+		}
+
+		return e
+	}
+
+	golden, err := os.ReadFile(file)
+	if err != nil {
+		e.t.Errorf("huskytest: %s, run with %s=1 to create it", err, UpdateEnv)
+		return e
+	}
+
+	if !bytes.Equal(golden, snapshot) {
+		e.t.Errorf("huskytest: response does not match %s\n--- expected\n%s\n--- actual\n%s", file, golden, snapshot)
+	}
+
+	return e
+}
+
+// snapshot renders the response as text with sorted headers
+func (e *Expectation) snapshot() []byte {
+	w := e.response.Recorder
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP %d\n", w.Code)
+
+	header := w.Header()
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+
+	b.WriteString("\n")
+	b.Write(w.Body.Bytes())
+
+	return b.Bytes()
+}
+
+func (e *Expectation) decode() (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(e.response.Recorder.Body.Bytes(), &v)
+	return v, err
+}
+
+// jsonEqual compares values by their JSON representation so ints match the
+// float64 numbers of decoded bodies
+func jsonEqual(expected interface{}, actual interface{}) bool {
+	b, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalized, actual)
+}
+
+func encode(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// lookup resolves a JSONPath of fields and indexes such as $.users[0].name
+func lookup(v interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %s must start with $", path)
+	}
+
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			rest = rest[end+1:]
+
+			object, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("JSONPath %s: %s is not an object", path, key)
+			}
+
+			if v, ok = object[key]; !ok {
+				return nil, fmt.Errorf("JSONPath %s: missing key %s", path, key)
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("JSONPath %s: missing ]", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if quoted := strings.Trim(selector, `'"`); quoted != selector {
+				object, ok := v.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("JSONPath %s: %s is not an object", path, quoted)
+				}
+
+				if v, ok = object[quoted]; !ok {
+					return nil, fmt.Errorf("JSONPath %s: missing key %s", path, quoted)
+				}
+				continue
+			}
+
+			var index int
+			if _, err := fmt.Sscanf(selector, "%d", &index); err != nil {
+				return nil, fmt.Errorf("JSONPath %s: invalid index %s", path, selector)
+			}
+
+			array, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("JSONPath %s: [%d] is not an array", path, index)
+			}
+
+			if index < 0 {
+				index += len(array)
+			}
+
+			if index < 0 || index >= len(array) {
+				return nil, fmt.Errorf("JSONPath %s: index %s out of range", path, selector)
+			}
+			v = array[index]
+		default:
+			return nil, fmt.Errorf("JSONPath %s: unexpected %q", path, rest[0])
+		}
+	}
+
+	return v, nil
+}
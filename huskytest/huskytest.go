@@ -0,0 +1,228 @@
+// Package huskytest provides helpers to test Husky handlers and services
+//
+//	huskytest.New(h).GET("/users/1").
+//		WithHeader("Authorization", "Bearer token").
+//		Expect(t).
+//		Status(200).
+//		JSONPath("$.name", "john")
+package huskytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/vetebase/husky"
+)
+
+// Client sends requests to a Husky service without a network listener
+type Client struct {
+	husky   *husky.Husky
+	headers http.Header
+}
+
+// New creates a client for the service
+func New(h *husky.Husky) *Client {
+	return &Client{husky: h, headers: http.Header{}}
+}
+
+// WithHeader sets a header sent with every request of the client
+func (c *Client) WithHeader(key string, value string) *Client {
+	c.headers.Set(key, value)
+	return c
+}
+
+// DELETE starts a DELETE request
+func (c *Client) DELETE(target string) *Request {
+	return c.Request("DELETE", target)
+}
+
+// GET starts a GET request
+func (c *Client) GET(target string) *Request {
+	return c.Request("GET", target)
+}
+
+// PATCH starts a PATCH request
+func (c *Client) PATCH(target string) *Request {
+	return c.Request("PATCH", target)
+}
+
+// POST starts a POST request
+func (c *Client) POST(target string) *Request {
+	return c.Request("POST", target)
+}
+
+// PUT starts a PUT request
+func (c *Client) PUT(target string) *Request {
+	return c.Request("PUT", target)
+}
+
+// Request starts a request with any method
+func (c *Client) Request(method string, target string) *Request {
+	return &Request{
+		client:  c,
+		method:  method,
+		target:  target,
+		headers: c.headers.Clone(),
+		query:   url.Values{},
+		params:  make(map[string]string),
+	}
+}
+
+// Request is a request being built
+type Request struct {
+	client  *Client
+	method  string
+	target  string
+	headers http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	params  map[string]string
+	body    []byte
+	err     error
+}
+
+// WithHeader sets a request header
+func (r *Request) WithHeader(key string, value string) *Request {
+	r.headers.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query param
+func (r *Request) WithQuery(key string, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie adds a cookie
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// WithParam sets a path param, only used by Call and Context since routed
+// requests take their params from the path
+func (r *Request) WithParam(key string, value string) *Request {
+	r.params[key] = value
+	return r
+}
+
+// WithBody sets the raw request body
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.headers.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets v encoded as JSON as the request body
+func (r *Request) WithJSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+	}
+
+	return r.WithBody(husky.MIMEApplicationJSON, b)
+}
+
+// WithForm sets the url encoded form as the request body
+func (r *Request) WithForm(form url.Values) *Request {
+	return r.WithBody("application/x-www-form-urlencoded", []byte(form.Encode()))
+}
+
+// HTTPRequest builds the *http.Request
+func (r *Request) HTTPRequest() *http.Request {
+	target := r.target
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req := httptest.NewRequest(r.method, target, body)
+	for k, v := range r.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+
+	return req
+}
+
+// Context builds a CTX with the path params for calling a handler directly,
+// call ctx.Cleanup() once done to close its request scoped resources
+func (r *Request) Context() (*husky.CTX, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	ctx := r.client.husky.NewContext(w, r.HTTPRequest())
+	ctx.AddParams(r.params)
+
+	return ctx, w
+}
+
+// Do sends the request through the routes and middleware of the service
+func (r *Request) Do() *Response {
+	if r.err != nil {
+		return &Response{Recorder: httptest.NewRecorder(), buildErr: r.err}
+	}
+
+	w := httptest.NewRecorder()
+	r.client.husky.ServeHTTP(w, r.HTTPRequest())
+
+	return &Response{Recorder: w}
+}
+
+// Call runs a single handler with the request, without routing or middleware
+// Errors returned by the handler are passed to the ErrorHandler of the service,
+// the CTX is cleaned up once the handler returns
+func (r *Request) Call(handler husky.Handler) *Response {
+	if r.err != nil {
+		return &Response{Recorder: httptest.NewRecorder(), buildErr: r.err}
+	}
+
+	ctx, w := r.Context()
+	defer ctx.Cleanup()
+
+	err := handler(ctx)
+	ctx.Error(err)
+
+	return &Response{Err: err, Recorder: w}
+}
+
+// Expect sends the request with Do and returns its assertions
+func (r *Request) Expect(t TestingT) *Expectation {
+	return r.Do().Expect(t)
+}
+
+// Response is the recorded response of a request
+type Response struct {
+	Recorder *httptest.ResponseRecorder
+	Err      error // error returned by the handler with Call
+
+	buildErr error
+}
+
+// Expect returns assertions on the response
+func (res *Response) Expect(t TestingT) *Expectation {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if res.buildErr != nil {
+		t.Errorf("huskytest: invalid request: %s", res.buildErr)
+	}
+
+	return &Expectation{t: t, response: res}
+}
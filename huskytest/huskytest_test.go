@@ -0,0 +1,159 @@
+package huskytest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+// recorder collects failed assertions instead of failing the test
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type user struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func service() *husky.Husky {
+	h := husky.New()
+
+	h.GET("/users/:id", func(ctx *husky.CTX) error {
+		if ctx.GetHeader("Authorization") != "token" {
+			return ctx.JSON(401, "Unauthorized")
+		}
+
+		return ctx.JSON(200, map[string]interface{}{
+			"id":   ctx.GetParam("id"),
+			"user": user{Name: "john", Roles: []string{"admin", "dev"}},
+			"page": ctx.GetParam("page"),
+		})
+	})
+
+	h.POST("/users", func(ctx *husky.CTX) error {
+		body, _ := ctx.Body()
+		return ctx.Blob(201, husky.MIMEApplicationJSON, body)
+	})
+
+	return h
+}
+
+func TestClientExpectations(t *testing.T) {
+	client := New(service()).WithHeader("Authorization", "token")
+
+	client.GET("/users/1").
+		WithQuery("page", "2").
+		Expect(t).
+		Status(200).
+		Header("Content-Type", "application/json").
+		JSONPath("$.id", "1").
+		JSONPath("$.page", "2").
+		JSONPath("$.user.name", "john").
+		JSONPath("$.user.roles[1]", "dev").
+		JSONPath("$['user'].roles[-1]", "dev")
+
+	client.POST("/users").
+		WithJSON(user{Name: "jane"}).
+		Expect(t).
+		Status(201).
+		JSON(map[string]interface{}{"name": "jane", "roles": nil})
+}
+
+func TestFailedExpectationsAreReported(t *testing.T) {
+	r := &recorder{}
+
+	New(service()).GET("/users/1").
+		Expect(r).
+		Status(200).
+		Body(`"Forbidden"`).
+		JSONPath("$.name", "john")
+
+	assert.Equal(t, []string{
+		"huskytest: expected status 200, got 401",
+		`huskytest: expected body "\"Forbidden\"", got "\"Unauthorized\""`,
+		"huskytest: JSONPath $.name: name is not an object",
+	}, r.errors)
+}
+
+func TestCallHandlerWithParams(t *testing.T) {
+	h := husky.New()
+
+	handler := func(ctx *husky.CTX) error {
+		if ctx.GetParam("id") == "0" {
+			return errors.New("not found")
+		}
+
+		return ctx.String(200, "user "+ctx.GetParam("id"))
+	}
+
+	New(h).GET("/").WithParam("id", "7").Call(handler).Expect(t).Status(200).Body("user 7")
+
+	res := New(h).GET("/").WithParam("id", "0").Call(handler)
+	assert.EqualError(t, res.Err, "not found")
+	res.Expect(t).Status(500)
+
+	ctx, _ := New(h).GET("/").WithParam("id", "3").Context()
+	defer ctx.Cleanup()
+	assert.Equal(t, "3", ctx.GetParam("id"))
+}
+
+type closer struct {
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestCallCleansUp(t *testing.T) {
+	resource := &closer{}
+
+	h := husky.New()
+	husky.Provide[*closer](h, husky.LifetimeRequest, func() *closer {
+		return resource
+	})
+
+	New(h).GET("/").Call(func(ctx *husky.CTX) error {
+		husky.MustResolve[*closer](ctx)
+		assert.False(t, resource.closed)
+		return nil
+	})
+
+	assert.True(t, resource.closed)
+}
+
+func TestGolden(t *testing.T) {
+	GoldenDir = t.TempDir()
+	defer func() { GoldenDir = "testdata" }()
+
+	client := New(service()).WithHeader("Authorization", "token")
+
+	r := &recorder{}
+	client.GET("/users/1").Expect(r).Golden("user")
+	assert.Len(t, r.errors, 1)
+
+	t.Setenv(UpdateEnv, "1")
+	client.GET("/users/1").Expect(t).Golden("user")
+
+	golden, _ := os.ReadFile(filepath.Join(GoldenDir, "user.golden"))
+	assert.Equal(t, "HTTP 200\nContent-Type: application/json\n\n"+
+		`{"id":"1","page":"","user":{"name":"john","roles":["admin","dev"]}}`, string(golden))
+
+	os.Unsetenv(UpdateEnv)
+	client.GET("/users/1").Expect(t).Golden("user")
+
+	r = &recorder{}
+	client.GET("/users/2").Expect(r).Golden("user")
+	assert.Len(t, r.errors, 1)
+}
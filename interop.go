@@ -0,0 +1,100 @@
+package husky
+
+import (
+	"context"
+	"net/http"
+)
+
+// ctxKey holds the CTX on requests passed to net/http code, so handlers
+// converted back with ToHTTPHandler or ToHTTPMiddleware share its state
+type ctxKey struct{}
+
+// WrapHandler converts a net/http handler into a Husky handler, the handler
+// writes through ctx.Response so its status and size are tracked
+func WrapHandler(handler http.Handler) Handler {
+	return func(ctx *CTX) error {
+		handler.ServeHTTP(ctx.Response, withCTX(ctx))
+		return nil
+	}
+}
+
+// WrapMiddleware converts net/http middleware into Husky middleware
+// Requests and response writers replaced by the middleware are used for the
+// rest of the chain, the error of the next handler is returned unchanged.
+// Only the writer is restored afterwards so values set with ctx.Set further
+// down the chain stay visible to outer middleware.
+func WrapMiddleware(middleware func(http.Handler) http.Handler) MiddlewareHandler {
+	return func(next Handler) Handler {
+		return func(ctx *CTX) (err error) {
+			response := ctx.Response
+
+			middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx.Request = r
+				if w != http.ResponseWriter(response) {
+					ctx.Response = NewResponse(w)
+				}
+
+				err = next(ctx)
+
+				ctx.Response = response
+			})).ServeHTTP(response, withCTX(ctx))
+
+			return err
+		}
+	}
+}
+
+// ToHTTPHandler converts a Husky handler into a net/http handler. Inside a
+// Husky service the CTX of the request is reused, otherwise a new CTX is
+// created and errors go to DefaultErrorHandler.
+func ToHTTPHandler(handler Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, done := adaptCTX(w, r)
+		defer done()
+
+		ctx.Error(handler(ctx))
+	})
+}
+
+// ToHTTPMiddleware converts Husky middleware into net/http middleware
+func ToHTTPMiddleware(middleware MiddlewareHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, done := adaptCTX(w, r)
+			defer done()
+
+			handler := middleware(func(ctx *CTX) error {
+				next.ServeHTTP(ctx.Response, withCTX(ctx))
+				return nil
+			})
+
+			ctx.Error(handler(ctx))
+		})
+	}
+}
+
+// withCTX returns the request carrying ctx in its context
+func withCTX(ctx *CTX) *http.Request {
+	return ctx.Request.WithContext(context.WithValue(ctx.Context(), ctxKey{}, ctx))
+}
+
+// adaptCTX returns the CTX carried by the request, updated to the request and
+// writer, or a new CTX. done restores the writer or cleans up the CTX.
+func adaptCTX(w http.ResponseWriter, r *http.Request) (*CTX, func()) {
+	ctx, ok := r.Context().Value(ctxKey{}).(*CTX)
+	if !ok {
+		ctx = &CTX{Request: r, Response: NewResponse(w), cleanups: &cleanupQueue{}}
+		return ctx, ctx.Cleanup
+	}
+
+	response := ctx.Response
+
+	ctx.Request = r
+	if w != http.ResponseWriter(response) {
+		ctx.Response = NewResponse(w)
+	}
+
+	return ctx, func() {
+		ctx.Response = response
+	}
+}
@@ -0,0 +1,241 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type interopKey struct{}
+
+func TestWrapHandler(t *testing.T) {
+	h := New()
+
+	var status int
+	var size int64
+	h.GET("/", WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(201)
+		w.Write([]byte("created"))
+	})), func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			err := next(ctx)
+			status, size = ctx.Response.Status, ctx.Response.Size
+			return err
+		}
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "created", w.Body.String())
+	assert.Equal(t, 201, status)
+	assert.Equal(t, int64(7), size)
+}
+
+func TestWrapMiddleware(t *testing.T) {
+	h := New()
+
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", 401)
+				return
+			}
+
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), interopKey{}, "from std")))
+		})
+	}
+
+	h.Middlware(WrapMiddleware(std))
+	h.GET("/", func(ctx *CTX) error {
+		user, _ := ctx.Get("user")
+		return ctx.String(200, ctx.Context().Value(interopKey{}).(string)+" "+user.(string))
+	}, func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			ctx.Set("user", "john")
+			return next(ctx)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 401, w.Code)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Std"))
+	assert.Equal(t, "from std john", w.Body.String())
+}
+
+func TestWrapMiddlewareError(t *testing.T) {
+	h := New()
+
+	var handled error
+	h.ErrorHandler = func(err error, ctx *CTX) {
+		handled = err
+		ctx.String(503, "unavailable")
+	}
+
+	wrapped := false
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// response writers replaced by the middleware are used downstream
+			next.ServeHTTP(&headerWriter{ResponseWriter: w, wrapped: &wrapped}, r)
+		})
+	}
+
+	errDown := errors.New("down")
+	var status int
+	h.GET("/", func(ctx *CTX) error {
+		return errDown
+	}, func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			err := next(ctx)
+			ctx.Error(err)
+			status = ctx.Response.Status
+			return err
+		}
+	}, WrapMiddleware(std))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, errDown, handled)
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, 503, status)
+	assert.True(t, wrapped)
+}
+
+func TestWrapMiddlewareKeepsValues(t *testing.T) {
+	h := New()
+
+	passthrough := func(next http.Handler) http.Handler {
+		return next
+	}
+
+	// values set below the wrapped middleware are seen by outer middleware
+	var user interface{}
+	h.GET("/", func(ctx *CTX) error {
+		return ctx.String(200, "ok")
+	}, func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			ctx.Set("user", "bob")
+			return next(ctx)
+		}
+	}, WrapMiddleware(passthrough), func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			err := next(ctx)
+			user, _ = ctx.Get("user")
+			return err
+		}
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "bob", user)
+}
+
+func TestToHTTPHandler(t *testing.T) {
+	handler := ToHTTPHandler(func(ctx *CTX) error {
+		if ctx.GetParam("fail") != "" {
+			return errors.New("failed")
+		}
+
+		return ctx.JSON(200, ctx.GetParam("name"))
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?name=john", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"john"`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?fail=1", nil))
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestToHTTPHandlerReusesCTX(t *testing.T) {
+	h := New()
+
+	// Husky -> net/http -> Husky shares the values and error handler
+	var handled error
+	h.ErrorHandler = func(err error, ctx *CTX) {
+		handled = err
+		ctx.String(418, err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", ToHTTPHandler(func(ctx *CTX) error {
+		user, _ := ctx.Get("user")
+		return errors.New(user.(string))
+	}))
+
+	h.GET("/", WrapHandler(mux), func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			ctx.Set("user", "john")
+			return next(ctx)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.EqualError(t, handled, "john")
+	assert.Equal(t, 418, w.Code)
+	assert.Equal(t, "john", w.Body.String())
+}
+
+func TestToHTTPMiddleware(t *testing.T) {
+	middleware := ToHTTPMiddleware(func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			if ctx.GetHeader("Authorization") == "" {
+				return ctx.String(401, "unauthorized")
+			}
+
+			ctx.Set("user", "john")
+			ctx.SetHeader("X-Husky", "1")
+			return next(ctx)
+		}
+	})
+
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := Value(r.Context(), "user")
+		w.Write([]byte(user.(string)))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 401, w.Code)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Husky"))
+	assert.Equal(t, "john", w.Body.String())
+}
+
+// headerWriter marks responses written through it
+type headerWriter struct {
+	http.ResponseWriter
+	wrapped *bool
+}
+
+func (w *headerWriter) WriteHeader(code int) {
+	*w.wrapped = true
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,219 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Hook runs when the service starts or stops
+type Hook func(ctx context.Context) error
+
+// stopHook remembers how many start hooks were registered before it, so a
+// failed startup only stops what was started
+type stopHook struct {
+	hook  Hook
+	after int
+}
+
+// OnStart adds a hook run in registration order before the server listens,
+// an error aborts the startup
+func (husky *Husky) OnStart(hook Hook) {
+	husky.mu.Lock()
+	defer husky.mu.Unlock()
+
+	husky.startHooks = append(husky.startHooks, hook)
+}
+
+// OnStop adds a hook run in reverse registration order after the server
+// drained, e.g. to close the database pool opened by an OnStart hook
+func (husky *Husky) OnStop(hook Hook) {
+	husky.mu.Lock()
+	defer husky.mu.Unlock()
+
+	husky.stopHooks = append(husky.stopHooks, stopHook{hook: hook, after: len(husky.startHooks)})
+}
+
+// Start validates the providers, runs the OnStart hooks and serves requests
+// until SIGINT or SIGTERM, then gracefully shuts down and runs the OnStop
+// hooks. START_TIMEOUT (15s) bounds the start hooks and SHUTDOWN_TIMEOUT (30s)
+// the shutdown.
+func (husky *Husky) Start() error {
+	if err := husky.ValidateServices(); err != nil {
+		return err
+	}
+
+	server := husky.server()
+	config := husky.Config.Load()
+
+	startCtx, cancel := context.WithTimeout(context.Background(), duration(config, "START_TIMEOUT", 15*time.Second))
+	err := husky.start(startCtx)
+	cancel()
+
+	shutdownTimeout := duration(config, "SHUTDOWN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return errors.Join(err, husky.stop(stopCtx))
+	}
+
+	done := make(chan struct{})
+
+	husky.mu.Lock()
+	husky.httpServer = server
+	husky.shutdownDone = done
+	husky.mu.Unlock()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err == http.ErrServerClosed {
+			// Shutdown was called, wait until it completes
+			<-done
+			return nil
+		}
+
+		log.Printf("Server error: %s", err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return errors.Join(err, husky.Shutdown(ctx))
+	case <-signals.Done():
+		log.Printf("Shutting down")
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return husky.Shutdown(ctx)
+	}
+}
+
+// Shutdown fails readiness and keeps serving for SHUTDOWN_DELAY (0s) so load
+// balancers stop routing to the service, then gracefully stops the server
+// waiting for active requests until ctx is done and runs the OnStop hooks
+func (husky *Husky) Shutdown(ctx context.Context) error {
+	return husky.shutdown(ctx, duration(husky.Config.Load(), "SHUTDOWN_DELAY", 0))
+}
+
+// shutdown waits delay between failing readiness and stopping the server
+func (husky *Husky) shutdown(ctx context.Context, delay time.Duration) error {
+	husky.mu.Lock()
+	husky.draining = true
+	server := husky.httpServer
+	husky.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	var err error
+	if server != nil {
+		err = server.Shutdown(ctx)
+	}
+
+	err = errors.Join(err, husky.stop(ctx))
+
+	husky.mu.Lock()
+	if husky.shutdownDone != nil {
+		close(husky.shutdownDone)
+		husky.shutdownDone = nil
+	}
+	husky.mu.Unlock()
+
+	return err
+}
+
+// start runs the start hooks in order until one fails
+func (husky *Husky) start(ctx context.Context) error {
+	husky.mu.Lock()
+	hooks := append([]Hook(nil), husky.startHooks...)
+	husky.running = true
+	husky.started = 0
+	husky.mu.Unlock()
+
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook); err != nil {
+			return err
+		}
+
+		husky.mu.Lock()
+		husky.started++
+		husky.mu.Unlock()
+	}
+
+	return nil
+}
+
+// stop runs the stop hooks of the started hooks in reverse order, every hook
+// runs even if an earlier one failed, then closes the singletons
+func (husky *Husky) stop(ctx context.Context) error {
+	husky.mu.Lock()
+	running, started := husky.running, husky.started
+	husky.running = false
+	hooks := append([]stopHook(nil), husky.stopHooks...)
+	husky.mu.Unlock()
+
+	// never started or already stopped
+	if !running {
+		return nil
+	}
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].after > started {
+			continue
+		}
+
+		if err := runHook(ctx, hooks[i].hook); err != nil {
+			log.Printf("Stop hook error: %s", err)
+			errs = append(errs, err)
+		}
+	}
+
+	// the hooks may still use the singletons, close them last
+	if err := husky.services().close(); err != nil {
+		log.Printf("Service close error: %s", err)
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHook runs the hook until it returns or ctx is done, a hook ignoring
+// its context is left running in the background
+func runHook(ctx context.Context, hook Hook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
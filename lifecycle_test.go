@@ -0,0 +1,73 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordHook appends name to calls when run
+func recordHook(calls *[]string, name string, err error) Hook {
+	return func(ctx context.Context) error {
+		*calls = append(*calls, name)
+		return err
+	}
+}
+
+func TestLifecycleHookOrder(t *testing.T) {
+	h := New()
+
+	var calls []string
+	h.OnStart(recordHook(&calls, "start db", nil))
+	h.OnStop(recordHook(&calls, "stop db", nil))
+	h.OnStart(recordHook(&calls, "start cache", nil))
+	h.OnStop(recordHook(&calls, "stop cache", nil))
+
+	assert.NoError(t, h.start(context.Background()))
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	// a second shutdown does not run the hooks again
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"start db", "start cache", "stop cache", "stop db"}, calls)
+}
+
+func TestLifecycleStartErrorStopsStartedHooks(t *testing.T) {
+	h := New()
+
+	var calls []string
+	h.OnStart(recordHook(&calls, "start db", nil))
+	h.OnStop(recordHook(&calls, "stop db", nil))
+	h.OnStart(recordHook(&calls, "start cache", errors.New("cache unavailable")))
+	h.OnStop(recordHook(&calls, "stop cache", nil))
+
+	assert.EqualError(t, h.Start(), "cache unavailable")
+	assert.Equal(t, []string{"start db", "start cache", "stop db"}, calls)
+}
+
+func TestLifecycleHookTimeout(t *testing.T) {
+	h := New()
+
+	h.OnStart(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, h.start(ctx))
+}
+
+func TestShutdownWithoutStartSkipsStopHooks(t *testing.T) {
+	h := New()
+
+	var calls []string
+	h.OnStop(recordHook(&calls, "stop", nil))
+
+	assert.NoError(t, h.Shutdown(context.Background()))
+	assert.Empty(t, calls)
+}
@@ -0,0 +1,337 @@
+package husky
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets in seconds used for request latencies
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics is a registry of counters, gauges and histograms exposed in the
+// Prometheus text format
+type Metrics struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+// metric is implemented by every metric vector of the registry
+type metric interface {
+	kind() string
+	write(w *bufio.Writer)
+}
+
+// NewMetrics creates an empty metrics registry
+func NewMetrics() *Metrics {
+	return &Metrics{metrics: make(map[string]metric)}
+}
+
+// Counter returns the counter registered as name, creating it on first use
+// Panics if name is registered as another metric type
+func (m *Metrics) Counter(name string, help string, labels ...string) *CounterVec {
+	return m.register(name, "counter", func() metric {
+		return &CounterVec{vec: newVec(name, help, labels)}
+	}).(*CounterVec)
+}
+
+// Gauge returns the gauge registered as name, creating it on first use
+// Panics if name is registered as another metric type
+func (m *Metrics) Gauge(name string, help string, labels ...string) *GaugeVec {
+	return m.register(name, "gauge", func() metric {
+		return &GaugeVec{vec: newVec(name, help, labels)}
+	}).(*GaugeVec)
+}
+
+// Histogram returns the histogram registered as name, creating it on first use
+// Panics if name is registered as another metric type
+func (m *Metrics) Histogram(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	return m.register(name, "histogram", func() metric {
+		if len(buckets) == 0 {
+			buckets = DefaultBuckets
+		}
+
+		sorted := append([]float64(nil), buckets...)
+		sort.Float64s(sorted)
+
+		return &HistogramVec{vec: newVec(name, help, labels), buckets: sorted}
+	}).(*HistogramVec)
+}
+
+func (m *Metrics) register(name string, kind string, create func() metric) metric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.metrics == nil {
+		m.metrics = make(map[string]metric)
+	}
+
+	if existing, ok := m.metrics[name]; ok {
+		if existing.kind() != kind {
+			panic("husky: metric " + name + " already registered as " + existing.kind())
+		}
+
+		return existing
+	}
+
+	created := create()
+	m.metrics[name] = created
+	return created
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.metrics))
+	for name := range m.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, m.metrics[name])
+	}
+	m.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+	for _, metric := range metrics {
+		metric.write(b)
+	}
+
+	return b.Flush()
+}
+
+// MetricsEndpoint serves the metrics of the service at endpoint
+func (husky *Husky) MetricsEndpoint(endpoint string, middleware ...MiddlewareHandler) {
+	husky.add("GET", endpoint, func(ctx *CTX) error {
+		ctx.Response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		ctx.Response.WriteHeader(200)
+
+		return husky.Metrics.WritePrometheus(ctx.Response)
+	}, middleware)
+}
+
+// vec holds the series of a metric by label values
+type vec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	series map[string]*series
+}
+
+type series struct {
+	values  []string
+	value   float64
+	buckets []uint64
+	count   uint64
+}
+
+func newVec(name string, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, series: make(map[string]*series)}
+}
+
+// get returns the series for the label values, callers must hold the lock
+// Panics if the number of values does not match the labels
+func (v *vec) get(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic("husky: metric " + v.name + " expects " + strconv.Itoa(len(v.labels)) + " label values")
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+
+	return s
+}
+
+// sorted returns the series ordered by label values, callers must hold the lock
+func (v *vec) sorted() []*series {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]*series, len(keys))
+	for i, k := range keys {
+		sorted[i] = v.series[k]
+	}
+
+	return sorted
+}
+
+func (v *vec) header(w *bufio.Writer, kind string) {
+	w.WriteString("# HELP " + v.name + " " + escapeHelp(v.help) + "\n")
+	w.WriteString("# TYPE " + v.name + " " + kind + "\n")
+}
+
+// sample writes a single line, extra is an additional label such as le
+func (v *vec) sample(w *bufio.Writer, name string, values []string, extra string, value float64) {
+	w.WriteString(name)
+
+	if len(values) > 0 || extra != "" {
+		pairs := make([]string, 0, len(values)+1)
+		for i, value := range values {
+			pairs = append(pairs, v.labels[i]+`="`+escapeLabel(value)+`"`)
+		}
+		if extra != "" {
+			pairs = append(pairs, extra)
+		}
+
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec
+}
+
+// Inc increments the counter of the label values by one
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the counter of the label values, delta must not be negative
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic("husky: counter " + c.name + " can not decrease")
+	}
+
+	c.mu.Lock()
+	c.get(values).value += delta
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) kind() string {
+	return "counter"
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.header(w, "counter")
+	for _, s := range c.sorted() {
+		c.sample(w, c.name, s.values, "", s.value)
+	}
+}
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct {
+	vec
+}
+
+// Set sets the gauge of the label values
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.mu.Lock()
+	g.get(values).value = value
+	g.mu.Unlock()
+}
+
+// Add adds delta to the gauge of the label values
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.mu.Lock()
+	g.get(values).value += delta
+	g.mu.Unlock()
+}
+
+// Inc increments the gauge of the label values by one
+func (g *GaugeVec) Inc(values ...string) {
+	g.Add(1, values...)
+}
+
+// Dec decrements the gauge of the label values by one
+func (g *GaugeVec) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+func (g *GaugeVec) kind() string {
+	return "gauge"
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.header(w, "gauge")
+	for _, s := range g.sorted() {
+		g.sample(w, g.name, s.values, "", s.value)
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec
+	buckets []float64
+}
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(values)
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(h.buckets))
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+
+	s.value += value
+	s.count++
+}
+
+func (h *HistogramVec) kind() string {
+	return "histogram"
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header(w, "histogram")
+	for _, s := range h.sorted() {
+		for i, bound := range h.buckets {
+			h.sample(w, h.name+"_bucket", s.values, `le="`+formatFloat(bound)+`"`, float64(s.buckets[i]))
+		}
+		h.sample(w, h.name+"_bucket", s.values, `le="+Inf"`, float64(s.count))
+		h.sample(w, h.name+"_sum", s.values, "", s.value)
+		h.sample(w, h.name+"_count", s.values, "", float64(s.count))
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
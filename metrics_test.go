@@ -0,0 +1,56 @@
+package husky
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	m := NewMetrics()
+
+	m.Counter("jobs_total", "Processed jobs.", "queue").Add(3, "mail")
+	m.Gauge("workers", "Running workers.").Set(4)
+	m.Histogram("job_seconds", "Job duration.", []float64{1, 0.5}).Observe(0.7)
+
+	var b strings.Builder
+	assert.NoError(t, m.WritePrometheus(&b))
+
+	assert.Equal(t, `# HELP job_seconds Job duration.
+# TYPE job_seconds histogram
+job_seconds_bucket{le="0.5"} 0
+job_seconds_bucket{le="1"} 1
+job_seconds_bucket{le="+Inf"} 1
+job_seconds_sum 0.7
+job_seconds_count 1
+# HELP jobs_total Processed jobs.
+# TYPE jobs_total counter
+jobs_total{queue="mail"} 3
+# HELP workers Running workers.
+# TYPE workers gauge
+workers 4
+`, b.String())
+}
+
+func TestMetricsEscapesLabels(t *testing.T) {
+	m := NewMetrics()
+	m.Counter("requests_total", "Requests.", "path").Inc("a\"b\\c\nd")
+
+	var b strings.Builder
+	m.WritePrometheus(&b)
+
+	assert.Contains(t, b.String(), `requests_total{path="a\"b\\c\nd"} 1`)
+}
+
+func TestMetricsRegisterReturnsExisting(t *testing.T) {
+	m := NewMetrics()
+
+	assert.True(t, m.Counter("hits", "Hits.") == m.Counter("hits", "Hits."))
+	assert.Panics(t, func() {
+		m.Gauge("hits", "Hits.")
+	})
+	assert.Panics(t, func() {
+		m.Counter("hits", "Hits.").Inc("unexpected")
+	})
+}
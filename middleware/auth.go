@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/vetebase/husky"
+)
+
+// PrincipalKey holds the authenticated principal, use PrincipalKey.From to
+// read it from a context.Context
+var PrincipalKey = husky.NewKey[string]("principal")
+
+// Principal returns the authenticated principal stored on the CTX by the
+// BasicAuth or KeyAuth middleware
+func Principal(ctx *husky.CTX) (string, bool) {
+	return PrincipalKey.Get(ctx)
+}
+
+// setPrincipal stores the authenticated principal on the CTX
+func setPrincipal(ctx *husky.CTX, principal string) {
+	PrincipalKey.Set(ctx, principal)
+}
+
+// unauthorized sends a 401 response with the authentication challenge
+func unauthorized(ctx *husky.CTX, challenge string) error {
+	ctx.SetHeader("WWW-Authenticate", challenge)
+	return ctx.JSON(http.StatusUnauthorized, "Unauthorized")
+}
+
+// AuthError returns a Husky Handler when a validator fails
+func AuthError(ctx *husky.CTX) error {
+	return ctx.JSON(http.StatusInternalServerError, "Authentication Error")
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/vetebase/husky"
+)
+
+// BasicAuthValidator validates the credentials of a request
+type BasicAuthValidator func(user string, password string, ctx *husky.CTX) (bool, error)
+
+// BasicAuthConfig configuration for BasicAuth middleware
+type BasicAuthConfig struct {
+	Realm     string
+	Validator BasicAuthValidator
+}
+
+// DefaultBasicAuthConfig handles the default BasicAuth configuration for Husky
+var DefaultBasicAuthConfig = BasicAuthConfig{
+	Realm: "Restricted",
+}
+
+// BasicAuth middleware for Husky routes
+func BasicAuth(validator BasicAuthValidator) func(next husky.Handler) husky.Handler {
+	config := DefaultBasicAuthConfig
+	config.Validator = validator
+
+	return BasicAuthConfigured(config)
+}
+
+// BasicAuthConfigured returns a configured BasicAuth middleware
+// Panics if no validator is set
+func BasicAuthConfigured(config BasicAuthConfig) func(next husky.Handler) husky.Handler {
+	if config.Validator == nil {
+		panic("husky: basic auth middleware requires a validator")
+	}
+
+	if config.Realm == "" {
+		config.Realm = DefaultBasicAuthConfig.Realm
+	}
+
+	challenge := "Basic realm=" + strconv.Quote(config.Realm)
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			user, password, ok := parseBasicAuth(ctx.GetHeader("Authorization"))
+			if !ok {
+				return unauthorized(ctx, challenge)
+			}
+
+			valid, err := config.Validator(user, password, ctx)
+			if err != nil {
+				return AuthError(ctx)
+			}
+
+			if !valid {
+				return unauthorized(ctx, challenge)
+			}
+
+			setPrincipal(ctx, user)
+			return next(ctx)
+		}
+	}
+}
+
+// BasicAuthUsers returns a validator checking credentials against a map of
+// user to password, comparisons are done in constant time
+func BasicAuthUsers(users map[string]string) BasicAuthValidator {
+	hashed := make(map[string][32]byte, len(users))
+	for user, password := range users {
+		hashed[user] = sha256.Sum256([]byte(password))
+	}
+
+	// compare against a dummy digest for unknown users to keep timing equal
+	missing := sha256.Sum256([]byte{})
+
+	return func(user string, password string, ctx *husky.CTX) (bool, error) {
+		expected, ok := hashed[user]
+		if !ok {
+			expected = missing
+		}
+
+		actual := sha256.Sum256([]byte(password))
+		match := subtle.ConstantTimeCompare(expected[:], actual[:]) == 1
+
+		return ok && match, nil
+	}
+}
+
+// parseBasicAuth decodes the user and password of a Basic Authorization header
+func parseBasicAuth(header string) (user string, password string, ok bool) {
+	const prefix = "basic "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return
+	}
+
+	credentials := string(decoded)
+	i := strings.IndexByte(credentials, ':')
+	if i < 0 {
+		return
+	}
+
+	return credentials[:i], credentials[i+1:], true
+}
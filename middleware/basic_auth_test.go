@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func principalHandler(ctx *husky.CTX) error {
+	principal, _ := Principal(ctx)
+	return ctx.String(200, principal)
+}
+
+func TestBasicAuthAcceptsValidCredentials(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+
+	auth := BasicAuth(BasicAuthUsers(map[string]string{"admin": "secret"}))
+	auth(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "admin", w.Body.String())
+}
+
+func TestBasicAuthRejectsInvalidCredentials(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("admin", "wrong")
+	w := httptest.NewRecorder()
+
+	auth := BasicAuthConfigured(BasicAuthConfig{
+		Realm:     "Admin",
+		Validator: BasicAuthUsers(map[string]string{"admin": "secret"}),
+	})
+	auth(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Basic realm="Admin"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestBasicAuthRequiresHeader(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	auth := BasicAuth(BasicAuthUsers(map[string]string{"admin": "secret"}))
+	auth(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Basic realm="Restricted"`, w.Header().Get("WWW-Authenticate"))
+}
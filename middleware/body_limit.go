@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vetebase/husky"
+)
+
+// BodyLimitConfig configuration for BodyLimit middleware
+type BodyLimitConfig struct {
+	Limit string // maximum body size, e.g. "512KB", "2MB" or "1GB"
+}
+
+// ErrBodyTooLarge is returned when reading past the configured body limit
+var ErrBodyTooLarge = errors.New("request body too large")
+
+var sizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+	"T":  1 << 40,
+	"TB": 1 << 40,
+}
+
+// BodyLimitError returns a Husky Handler when the body limit is exceeded
+func BodyLimitError(ctx *husky.CTX) error {
+	return ctx.JSON(http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+}
+
+// BodyLimit middleware for Husky routes
+func BodyLimit(limit string) func(next husky.Handler) husky.Handler {
+	return BodyLimitConfigured(BodyLimitConfig{Limit: limit})
+}
+
+// BodyLimitConfigured returns a configured BodyLimit middleware
+// Panics if the limit can not be parsed
+func BodyLimitConfigured(config BodyLimitConfig) func(next husky.Handler) husky.Handler {
+	limit, err := parseSize(config.Limit)
+	if err != nil {
+		panic("husky: invalid body limit: " + err.Error())
+	}
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			req := ctx.Request
+
+			// reject declared sizes up front
+			if req.ContentLength > limit {
+				return BodyLimitError(ctx)
+			}
+
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(ctx)
+			}
+
+			// chunked and undeclared bodies are counted as they are read
+			reader := &limitedReader{ReadCloser: req.Body, limit: limit}
+			req.Body = reader
+
+			err := next(ctx)
+			if reader.exceeded && !ctx.Response.Committed {
+				return BodyLimitError(ctx)
+			}
+
+			return err
+		}
+	}
+}
+
+// limitedReader fails with ErrBodyTooLarge once more than limit bytes are read,
+// parent is set when the reader wraps a decompressed stream
+type limitedReader struct {
+	io.ReadCloser
+	limit    int64
+	read     int64
+	exceeded bool
+	parent   *limitedReader
+}
+
+func (r *limitedReader) Read(b []byte) (n int, err error) {
+	if r.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+
+	// read one byte past the limit to detect oversized bodies
+	if remaining := r.limit - r.read + 1; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+
+	n, err = r.ReadCloser.Read(b)
+	r.read += int64(n)
+
+	if r.read > r.limit {
+		r.exceeded = true
+		if r.parent != nil {
+			r.parent.exceeded = true
+		}
+
+		return n - int(r.read-r.limit), ErrBodyTooLarge
+	}
+
+	return n, err
+}
+
+// parseSize converts a human readable size such as "2MB" into bytes
+func parseSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+
+	i := strings.IndexFunc(size, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(size)
+	}
+
+	unit, ok := sizeUnits[strings.TrimSpace(size[i:])]
+	if !ok {
+		return 0, errors.New("unknown size unit in " + strconv.Quote(size))
+	}
+
+	value, err := strconv.ParseFloat(size[:i], 64)
+	if err != nil || value < 0 {
+		return 0, errors.New("invalid size " + strconv.Quote(size))
+	}
+
+	return int64(value * float64(unit)), nil
+}
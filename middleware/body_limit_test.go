@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+const JSON = `{"id":1,"name":"John Adams"}`
+
+func readBody(ctx *husky.CTX) error {
+	b, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	return ctx.String(200, string(b))
+}
+
+func TestBodyLimitAllowsSmallBodies(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(JSON))
+	w := httptest.NewRecorder()
+
+	BodyLimit("1KB")(readBody)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, JSON, w.Body.String())
+}
+
+func TestBodyLimitRejectsContentLength(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(strings.Repeat("a", 2048)))
+	w := httptest.NewRecorder()
+
+	BodyLimit("1KB")(readBody)(h.NewContext(w, r))
+
+	assert.Equal(t, 413, w.Code)
+}
+
+func TestBodyLimitRejectsChunkedBodies(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", ioutil.NopCloser(strings.NewReader(strings.Repeat("a", 2048))))
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+
+	err := BodyLimit("1KB")(readBody)(h.NewContext(w, r))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 413, w.Code)
+}
+
+func TestParseSize(t *testing.T) {
+	size, err := parseSize("2MB")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2<<20), size)
+
+	size, _ = parseSize("512kb")
+	assert.Equal(t, int64(512<<10), size)
+
+	size, _ = parseSize("100")
+	assert.Equal(t, int64(100), size)
+
+	_, err = parseSize("2XB")
+	assert.Error(t, err)
+}
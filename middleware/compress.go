@@ -0,0 +1,331 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/vetebase/husky"
+)
+
+const (
+	// EncodingBrotli is the brotli content encoding
+	EncodingBrotli = "br"
+
+	// EncodingDeflate is the deflate content encoding
+	EncodingDeflate = "deflate"
+
+	// EncodingGzip is the gzip content encoding
+	EncodingGzip = "gzip"
+)
+
+// CompressConfig configuration for Compress middleware
+type CompressConfig struct {
+	Encodings []string // supported encodings in order of server preference
+	Level     int      // compression level, 0 uses the encoder default
+	MinLength int      // bodies smaller than MinLength are sent uncompressed, a negative value compresses every body
+	SkipTypes []string // content type prefixes that are never compressed
+}
+
+// DefaultCompressConfig handles the default Compress configuration for Husky
+var DefaultCompressConfig = CompressConfig{
+	Encodings: []string{EncodingBrotli, EncodingGzip, EncodingDeflate},
+	MinLength: 1024,
+	SkipTypes: []string{
+		"image/",
+		"video/",
+		"audio/",
+		"font/woff",
+		"application/zip",
+		"application/gzip",
+		"application/x-gzip",
+		"application/x-brotli",
+		"application/x-bzip2",
+		"application/x-7z-compressed",
+		"application/x-rar-compressed",
+	},
+}
+
+// encoder is implemented by all pooled compression writers
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compress middleware for Husky routes using the default configuration
+func Compress() func(next husky.Handler) husky.Handler {
+	return CompressConfigured(DefaultCompressConfig)
+}
+
+// CompressConfigured returns a configured Compress middleware
+func CompressConfigured(config CompressConfig) func(next husky.Handler) husky.Handler {
+	if len(config.Encodings) == 0 {
+		config.Encodings = DefaultCompressConfig.Encodings
+	}
+
+	if config.MinLength == 0 {
+		config.MinLength = DefaultCompressConfig.MinLength
+	}
+
+	if config.SkipTypes == nil {
+		config.SkipTypes = DefaultCompressConfig.SkipTypes
+	}
+
+	pools := make(map[string]*sync.Pool)
+	for _, encoding := range config.Encodings {
+		if newEncoder(encoding, config.Level) == nil {
+			continue
+		}
+
+		encoding := encoding
+		pools[encoding] = &sync.Pool{New: func() interface{} {
+			return newEncoder(encoding, config.Level)
+		}}
+	}
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			res := ctx.Response
+			res.Header().Add("Vary", "Accept-Encoding")
+
+			// upgraded connections are hijacked and must not be wrapped
+			if ctx.GetHeader("Upgrade") != "" {
+				return next(ctx)
+			}
+
+			encoding := negotiateEncoding(ctx.GetHeader("Accept-Encoding"), config.Encodings)
+			pool, ok := pools[encoding]
+			if !ok {
+				return next(ctx)
+			}
+
+			writer := &compressWriter{
+				ResponseWriter: res.Writer,
+				config:         &config,
+				encoding:       encoding,
+				pool:           pool,
+			}
+
+			res.Writer = writer
+			defer func() {
+				writer.close()
+				res.Writer = writer.ResponseWriter
+			}()
+
+			return next(ctx)
+		}
+	}
+}
+
+// newEncoder creates an encoder for the encoding or nil if it is unsupported
+func newEncoder(encoding string, level int) encoder {
+	switch encoding {
+	case EncodingBrotli:
+		if level == 0 || level < brotli.BestSpeed || level > brotli.BestCompression {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(ioutil.Discard, level)
+	case EncodingGzip:
+		if level == 0 || level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			level = gzip.DefaultCompression
+		}
+		w, _ := gzip.NewWriterLevel(ioutil.Discard, level)
+		return w
+	case EncodingDeflate:
+		if level == 0 || level < flate.HuffmanOnly || level > flate.BestCompression {
+			level = flate.DefaultCompression
+		}
+		w, _ := flate.NewWriter(ioutil.Discard, level)
+		return w
+	}
+
+	return nil
+}
+
+// negotiateEncoding picks the supported encoding with the highest q-value,
+// ties are broken by the order of the supported encodings
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, q := parseQuality(part)
+		if name != "" {
+			accepted[strings.ToLower(name)] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// parseQuality splits a header element such as "gzip;q=0.8" into its value
+// and quality, elements without a q parameter have a quality of 1
+func parseQuality(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	value := strings.TrimSpace(params[0])
+	q := 1.0
+
+	for _, param := range params[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(param[2:], 64)
+		if err != nil {
+			return value, 0
+		}
+		q = parsed
+	}
+
+	return value, q
+}
+
+// compressWriter buffers the start of a response until it is known whether
+// the body should be compressed, then streams through a pooled encoder
+type compressWriter struct {
+	http.ResponseWriter
+	config   *CompressConfig
+	encoding string
+	pool     *sync.Pool
+	encoder  encoder
+	buffer   []byte
+	code     int
+	decided  bool
+}
+
+// WriteHeader delays the status until the compression decision is made
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.code != 0 {
+		return
+	}
+
+	w.code = code
+
+	// responses without a body and partial content ranges are passed through untouched
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusPartialContent {
+		w.decide(false)
+	}
+}
+
+// Write buffers until MinLength bytes are available, then compresses
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buffer = append(w.buffer, b...)
+	if len(w.buffer) < w.config.MinLength {
+		return len(b), nil
+	}
+
+	if err := w.decide(w.compressible()); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// Flush compresses eligible streamed responses regardless of their size
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// compressible checks the response headers to see if the body may be compressed
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+		header.Set("Content-Type", contentType)
+	}
+
+	for _, skip := range w.config.SkipTypes {
+		if strings.HasPrefix(contentType, skip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decide writes the delayed headers and flushes the buffered body
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+
+		w.encoder = w.pool.Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	_, err := w.Write(buffer)
+	return err
+}
+
+// close flushes small uncompressed bodies and returns the encoder to the pool
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.encoder.Reset(ioutil.Discard)
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func TestCompressGzipsLargeBodies(t *testing.T) {
+	h := husky.New()
+	body := strings.Repeat("husky ", 500)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	ctx := h.NewContext(w, r)
+	err := Compress()(func(ctx *husky.CTX) error {
+		return ctx.String(200, body)
+	})(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+		reader, err := gzip.NewReader(w.Body)
+		assert.NoError(t, err)
+
+		decoded, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, body, string(decoded))
+	}
+}
+
+func TestCompressSkipsSmallBodies(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	ctx := h.NewContext(w, r)
+	Compress()(func(ctx *husky.CTX) error {
+		return ctx.JSON(201, "small")
+	})(ctx)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, `"small"`, w.Body.String())
+}
+
+func TestCompressSkipsCompressedTypes(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	ctx := h.NewContext(w, r)
+	Compress()(func(ctx *husky.CTX) error {
+		ctx.SetHeader("Content-Type", "image/png")
+		_, err := ctx.Response.Write(make([]byte, 4096))
+		return err
+	})(ctx)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, 4096, w.Body.Len())
+}
+
+func TestCompressNegativeMinLength(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	ctx := h.NewContext(w, r)
+	CompressConfigured(CompressConfig{MinLength: -1})(func(ctx *husky.CTX) error {
+		return ctx.String(200, "small")
+	})(ctx)
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	reader, err := gzip.NewReader(w.Body)
+	if assert.NoError(t, err) {
+		decoded, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, "small", string(decoded))
+	}
+}
+
+func TestCompressSkipsPartialContent(t *testing.T) {
+	h := husky.New()
+	body := strings.Repeat("husky ", 500)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set("Range", "bytes=0-2999")
+	w := httptest.NewRecorder()
+
+	ctx := h.NewContext(w, r)
+	Compress()(func(ctx *husky.CTX) error {
+		ctx.SetHeader("Content-Range", "bytes 0-2999/6000")
+		return ctx.String(206, body[:3000])
+	})(ctx)
+
+	assert.Equal(t, 206, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, body[:3000], w.Body.String())
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := DefaultCompressConfig.Encodings
+
+	assert.Equal(t, "br", negotiateEncoding("gzip, deflate, br", supported))
+	assert.Equal(t, "gzip", negotiateEncoding("gzip;q=1.0, br;q=0.5", supported))
+	assert.Equal(t, "deflate", negotiateEncoding("deflate, gzip;q=0", supported))
+	assert.Equal(t, "br", negotiateEncoding("*", supported))
+	assert.Equal(t, "", negotiateEncoding("identity", supported))
+}
@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vetebase/husky"
+)
+
+// CSRFMode selects how the expected CSRF token is stored
+type CSRFMode int
+
+const (
+	// CSRFDoubleSubmit stores the token in a cookie, requests must echo it back
+	CSRFDoubleSubmit CSRFMode = iota
+
+	// CSRFSynchronizer stores the token server side, the cookie only holds an ID
+	CSRFSynchronizer
+)
+
+// CSRFStore holds synchronizer tokens server side
+type CSRFStore interface {
+	Get(id string) (token string, found bool)
+	Set(id string, token string, expiry time.Duration)
+}
+
+// CSRFConfig configuration for CSRF middleware
+type CSRFConfig struct {
+	Mode           CSRFMode
+	TokenLength    int    // random bytes per token, defaults to 32
+	TokenLookup    string // e.g. "header:X-CSRF-Token,form:_csrf,query:_csrf"
+	CookieName     string
+	CookiePath     string
+	CookieDomain   string
+	CookieMaxAge   int // seconds
+	CookieSecure   bool
+	CookieHTTPOnly bool
+	CookieSameSite http.SameSite
+	Store          CSRFStore // used by CSRFSynchronizer, defaults to an in memory store
+}
+
+// DefaultCSRFConfig handles the default CSRF configuration for Husky
+var DefaultCSRFConfig = CSRFConfig{
+	Mode:           CSRFDoubleSubmit,
+	TokenLength:    32,
+	TokenLookup:    "header:X-CSRF-Token,form:_csrf",
+	CookieName:     "_csrf",
+	CookiePath:     "/",
+	CookieMaxAge:   86400,
+	CookieSameSite: http.SameSiteLaxMode,
+}
+
+// CSRFTokenKey holds the CSRF token of the request
+// In CSRFSynchronizer mode new visitors only get a token once CSRFToken is called.
+var CSRFTokenKey = husky.NewKey[string]("csrf token")
+
+// csrfIssueKey holds the function issuing a synchronizer token on first use
+var csrfIssueKey = husky.NewKey[func() string]("csrf issue")
+
+// CSRFToken returns the CSRF token of the request so it can be embedded in
+// forms or templates
+func CSRFToken(ctx *husky.CTX) string {
+	if token, ok := CSRFTokenKey.Get(ctx); ok {
+		return token
+	}
+
+	if issue, ok := csrfIssueKey.Get(ctx); ok {
+		return issue()
+	}
+
+	return ""
+}
+
+// CSRF middleware for Husky routes using the default configuration
+func CSRF() func(next husky.Handler) husky.Handler {
+	return CSRFConfigured(DefaultCSRFConfig)
+}
+
+// CSRFConfigured returns a configured CSRF middleware
+// Panics if the token lookup is invalid
+func CSRFConfigured(config CSRFConfig) func(next husky.Handler) husky.Handler {
+	if config.TokenLength <= 0 {
+		config.TokenLength = DefaultCSRFConfig.TokenLength
+	}
+
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultCSRFConfig.TokenLookup
+	}
+
+	if config.CookieName == "" {
+		config.CookieName = DefaultCSRFConfig.CookieName
+	}
+
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultCSRFConfig.CookiePath
+	}
+
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = DefaultCSRFConfig.CookieMaxAge
+	}
+
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultCSRFConfig.CookieSameSite
+	}
+
+	if config.Mode == CSRFSynchronizer && config.Store == nil {
+		config.Store = NewMemoryCSRFStore()
+	}
+
+	parser, err := parseLookup(config.TokenLookup)
+	if err != nil {
+		panic("husky: " + err.Error())
+	}
+
+	expiry := time.Duration(config.CookieMaxAge) * time.Second
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			var cookieValue, token string
+
+			cookie, err := ctx.Cookie(config.CookieName)
+			if err == nil {
+				cookieValue = cookie.Value
+			}
+
+			setCookie := func(value string) {
+				ctx.SetCookie(&http.Cookie{
+					Name:     config.CookieName,
+					Value:    value,
+					Path:     config.CookiePath,
+					Domain:   config.CookieDomain,
+					MaxAge:   config.CookieMaxAge,
+					Expires:  time.Now().Add(expiry),
+					Secure:   config.CookieSecure,
+					HttpOnly: config.CookieHTTPOnly,
+					SameSite: config.CookieSameSite,
+				})
+			}
+
+			switch config.Mode {
+			case CSRFSynchronizer:
+				if stored, found := config.Store.Get(cookieValue); cookieValue != "" && found {
+					token = stored
+					config.Store.Set(cookieValue, token, expiry)
+				} else {
+					// only store a token once it is embedded in a page, so
+					// cookieless requests never fill the store
+					cookieValue = ""
+					csrfIssueKey.Set(ctx, func() string {
+						id, issued := randomToken(config.TokenLength), randomToken(config.TokenLength)
+						config.Store.Set(id, issued, expiry)
+						setCookie(id)
+
+						CSRFTokenKey.Set(ctx, issued)
+						return issued
+					})
+				}
+			default:
+				if cookieValue == "" {
+					cookieValue = randomToken(config.TokenLength)
+				}
+				token = cookieValue
+			}
+
+			if !isSafeMethod(ctx.Request.Method) {
+				sent, err := parser(ctx)
+				if err != nil {
+					return ctx.JSON(http.StatusBadRequest, "Missing CSRF Token")
+				}
+
+				if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+					return ctx.JSON(http.StatusForbidden, "Invalid CSRF Token")
+				}
+			}
+
+			if cookieValue != "" {
+				setCookie(cookieValue)
+			}
+			ctx.Response.Header().Add("Vary", "Cookie")
+
+			if token != "" {
+				CSRFTokenKey.Set(ctx, token)
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// isSafeMethod reports if the method is exempt from CSRF validation
+func isSafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+
+	return false
+}
+
+// randomToken returns length random bytes encoded as URL safe base64
+func randomToken(length int) string {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		panic("husky: could not generate random token: " + err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// MemoryCSRFStore is an in memory CSRFStore
+type MemoryCSRFStore struct {
+	mu     sync.Mutex
+	tokens map[string]csrfEntry
+	swept  time.Time
+}
+
+type csrfEntry struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemoryCSRFStore creates an empty in memory CSRFStore
+func NewMemoryCSRFStore() *MemoryCSRFStore {
+	return &MemoryCSRFStore{tokens: make(map[string]csrfEntry)}
+}
+
+// Get returns the token stored for id
+func (store *MemoryCSRFStore) Get(id string) (string, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.tokens[id]
+	if !ok || time.Now().After(entry.expires) {
+		delete(store.tokens, id)
+		return "", false
+	}
+
+	return entry.token, true
+}
+
+// Set stores the token for id, expired tokens are swept at most once a minute
+func (store *MemoryCSRFStore) Set(id string, token string, expiry time.Duration) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.swept) > time.Minute {
+		for k, entry := range store.tokens {
+			if now.After(entry.expires) {
+				delete(store.tokens, k)
+			}
+		}
+		store.swept = now
+	}
+
+	store.tokens[id] = csrfEntry{token: token, expires: now.Add(expiry)}
+}
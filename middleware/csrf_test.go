@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func tokenHandler(ctx *husky.CTX) error {
+	return ctx.String(200, CSRFToken(ctx))
+}
+
+func TestCSRFIssuesTokenOnSafeMethods(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	CSRF()(tokenHandler)(h.NewContext(w, r))
+
+	cookies := w.Result().Cookies()
+	if assert.Equal(t, 200, w.Code) && assert.Len(t, cookies, 1) {
+		assert.Equal(t, "_csrf", cookies[0].Name)
+		assert.Equal(t, cookies[0].Value, w.Body.String())
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	h := husky.New()
+	csrf := CSRF()
+
+	r, _ := http.NewRequest("POST", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_csrf", Value: "token"})
+	r.Header.Set("X-CSRF-Token", "token")
+	w := httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+
+	r, _ = http.NewRequest("POST", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_csrf", Value: "token"})
+	r.Header.Set("X-CSRF-Token", "other")
+	w = httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 403, w.Code)
+
+	r, _ = http.NewRequest("POST", "/", nil)
+	w = httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestCSRFSynchronizerToken(t *testing.T) {
+	h := husky.New()
+	csrf := CSRFConfigured(CSRFConfig{Mode: CSRFSynchronizer, CookieHTTPOnly: true})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	cookie := w.Result().Cookies()[0]
+	token := w.Body.String()
+	assert.NotEqual(t, cookie.Value, token)
+	assert.True(t, cookie.HttpOnly)
+
+	form := url.Values{"_csrf": {token}}
+	r, _ = http.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, token, w.Body.String())
+
+	// the cookie value alone is not a valid token
+	r, _ = http.NewRequest("POST", "/", nil)
+	r.Header.Set("X-CSRF-Token", cookie.Value)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestCSRFSynchronizerIssuesTokensOnDemand(t *testing.T) {
+	h := husky.New()
+	store := NewMemoryCSRFStore()
+	csrf := CSRFConfigured(CSRFConfig{Mode: CSRFSynchronizer, Store: store})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	csrf(handler)(h.NewContext(w, r))
+
+	assert.Empty(t, w.Result().Cookies())
+	assert.Empty(t, store.tokens)
+
+	r, _ = http.NewRequest("POST", "/", nil)
+	r.Header.Set("X-CSRF-Token", "token")
+	w = httptest.NewRecorder()
+	csrf(handler)(h.NewContext(w, r))
+
+	assert.Equal(t, 403, w.Code)
+	assert.Empty(t, store.tokens)
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	w = httptest.NewRecorder()
+	csrf(tokenHandler)(h.NewContext(w, r))
+
+	assert.Len(t, w.Result().Cookies(), 1)
+	assert.Len(t, store.tokens, 1)
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vetebase/husky"
+)
+
+var gzipReaders = sync.Pool{New: func() interface{} {
+	return new(gzip.Reader)
+}}
+
+// DecompressError returns a Husky Handler when the body is not valid gzip
+func DecompressError(ctx *husky.CTX) error {
+	return ctx.JSON(http.StatusBadRequest, "Invalid Content-Encoding")
+}
+
+// Decompress middleware transparently inflates gzip encoded request bodies
+// When combined with BodyLimit the limit applies to the decompressed size
+func Decompress() func(next husky.Handler) husky.Handler {
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			req := ctx.Request
+
+			encoding := strings.ToLower(req.Header.Get("Content-Encoding"))
+			if encoding != "gzip" && encoding != "x-gzip" {
+				return next(ctx)
+			}
+
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(ctx)
+			}
+
+			reader := gzipReaders.Get().(*gzip.Reader)
+
+			var body io.ReadCloser
+			switch err := reader.Reset(req.Body); err {
+			case nil:
+				// the reader goes back to the pool when the body is closed,
+				// or once the request is cleaned up if the handler never closes it
+				gz := &gzipBody{reader: reader, body: req.Body}
+				ctx.OnCleanup(func() { gz.Close() })
+				body = gz
+			case io.EOF:
+				gzipReaders.Put(reader)
+				body = http.NoBody
+			default:
+				gzipReaders.Put(reader)
+				return DecompressError(ctx)
+			}
+
+			// keep enforcing an outer BodyLimit against the inflated stream
+			if limited, ok := req.Body.(*limitedReader); ok {
+				body = &limitedReader{ReadCloser: body, limit: limited.limit, parent: limited}
+			}
+
+			req.Body = body
+			req.ContentLength = -1
+			req.Header.Del("Content-Encoding")
+			req.Header.Del("Content-Length")
+
+			return next(ctx)
+		}
+	}
+}
+
+// errBodyClosed is returned when reading a decompressed body after Close
+var errBodyClosed = errors.New("husky: read on closed body")
+
+// gzipBody closes both the gzip reader and the original request body, the
+// gzip reader is returned to the pool once and never used afterwards
+type gzipBody struct {
+	mu     sync.Mutex
+	reader *gzip.Reader
+	body   io.ReadCloser
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.reader == nil {
+		return 0, errBodyClosed
+	}
+
+	return b.reader.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.reader == nil {
+		return nil
+	}
+
+	b.reader.Close()
+	gzipReaders.Put(b.reader)
+	b.reader = nil
+
+	return b.body.Close()
+}
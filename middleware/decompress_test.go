@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func gzipped(s string) *bytes.Buffer {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return &b
+}
+
+func TestDecompressInflatesGzipBodies(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", gzipped(JSON))
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	Decompress()(readBody)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, JSON, w.Body.String())
+	assert.Empty(t, r.Header.Get("Content-Encoding"))
+}
+
+func TestDecompressAppliesLimitToInflatedSize(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", gzipped(strings.Repeat("a", 4096)))
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	BodyLimit("1KB")(Decompress()(readBody))(h.NewContext(w, r))
+
+	assert.Equal(t, 413, w.Code)
+}
+
+func TestDecompressRejectsInvalidGzip(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(JSON))
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	Decompress()(readBody)(h.NewContext(w, r))
+
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestDecompressSkipsOtherEncodings(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(JSON))
+	w := httptest.NewRecorder()
+
+	Decompress()(readBody)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, JSON, w.Body.String())
+}
+
+func TestDecompressBodyOutlivesMiddleware(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", gzipped(JSON))
+	r.Header.Set("Content-Encoding", "gzip")
+	ctx := h.NewContext(httptest.NewRecorder(), r)
+
+	// the handler keeps the body to read it after the middleware returned
+	Decompress()(func(ctx *husky.CTX) error {
+		return nil
+	})(ctx)
+
+	b, err := ioutil.ReadAll(ctx.Request.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, JSON, string(b))
+
+	// the pooled reader is released on Close and never read again
+	assert.NoError(t, ctx.Request.Body.Close())
+	assert.NoError(t, ctx.Request.Body.Close())
+
+	_, err = ctx.Request.Body.Read(make([]byte, 1))
+	assert.Equal(t, errBodyClosed, err)
+}
+
+func TestDecompressReleasesReaderOnCleanup(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("POST", "/", gzipped(JSON))
+	r.Header.Set("Content-Encoding", "gzip")
+	ctx := h.NewContext(httptest.NewRecorder(), r)
+
+	// the handler reads part of the body and never closes it
+	Decompress()(func(ctx *husky.CTX) error {
+		_, err := ctx.Request.Body.Read(make([]byte, 1))
+		return err
+	})(ctx)
+
+	ctx.Cleanup()
+
+	_, err := ctx.Request.Body.Read(make([]byte, 1))
+	assert.Equal(t, errBodyClosed, err)
+}
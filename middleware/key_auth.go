@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strconv"
+
+	"github.com/vetebase/husky"
+)
+
+// KeyAuthValidator validates an API key and returns the principal it belongs to
+type KeyAuthValidator func(key string, ctx *husky.CTX) (principal string, valid bool, err error)
+
+// KeyAuthConfig configuration for KeyAuth middleware
+type KeyAuthConfig struct {
+	KeyLookup string // e.g. "header:X-API-Key", "query:api_key" or "cookie:api_key"
+	Realm     string
+	Validator KeyAuthValidator
+}
+
+// DefaultKeyAuthConfig handles the default KeyAuth configuration for Husky
+var DefaultKeyAuthConfig = KeyAuthConfig{
+	KeyLookup: "header:X-API-Key",
+	Realm:     "Restricted",
+}
+
+// KeyAuth middleware for Husky routes
+func KeyAuth(lookup string, validator KeyAuthValidator) func(next husky.Handler) husky.Handler {
+	config := DefaultKeyAuthConfig
+	config.KeyLookup = lookup
+	config.Validator = validator
+
+	return KeyAuthConfigured(config)
+}
+
+// KeyAuthConfigured returns a configured KeyAuth middleware
+// Panics if no validator is set or the lookup is invalid
+func KeyAuthConfigured(config KeyAuthConfig) func(next husky.Handler) husky.Handler {
+	if config.Validator == nil {
+		panic("husky: key auth middleware requires a validator")
+	}
+
+	if config.KeyLookup == "" {
+		config.KeyLookup = DefaultKeyAuthConfig.KeyLookup
+	}
+
+	if config.Realm == "" {
+		config.Realm = DefaultKeyAuthConfig.Realm
+	}
+
+	parser, err := parseLookup(config.KeyLookup)
+	if err != nil {
+		panic("husky: " + err.Error())
+	}
+
+	challenge := "Key realm=" + strconv.Quote(config.Realm)
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			key, err := parser(ctx)
+			if err != nil {
+				return unauthorized(ctx, challenge)
+			}
+
+			principal, valid, err := config.Validator(key, ctx)
+			if err != nil {
+				return AuthError(ctx)
+			}
+
+			if !valid {
+				return unauthorized(ctx, challenge)
+			}
+
+			setPrincipal(ctx, principal)
+			return next(ctx)
+		}
+	}
+}
+
+// KeyAuthKeys returns a validator checking keys against a map of key to
+// principal, every key is compared in constant time
+func KeyAuthKeys(keys map[string]string) KeyAuthValidator {
+	type entry struct {
+		digest    [32]byte
+		principal string
+	}
+
+	entries := make([]entry, 0, len(keys))
+	for key, principal := range keys {
+		entries = append(entries, entry{sha256.Sum256([]byte(key)), principal})
+	}
+
+	return func(key string, ctx *husky.CTX) (string, bool, error) {
+		digest := sha256.Sum256([]byte(key))
+
+		principal, valid := "", false
+		for _, e := range entries {
+			if subtle.ConstantTimeCompare(e.digest[:], digest[:]) == 1 {
+				principal, valid = e.principal, true
+			}
+		}
+
+		return principal, valid, nil
+	}
+}
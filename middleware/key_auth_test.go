@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+var apiKeys = KeyAuthKeys(map[string]string{"key-1": "service-a"})
+
+func TestKeyAuthFromHeader(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("X-API-Key", "key-1")
+	w := httptest.NewRecorder()
+
+	KeyAuth("header:X-API-Key", apiKeys)(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "service-a", w.Body.String())
+}
+
+func TestKeyAuthFromQueryAndCookie(t *testing.T) {
+	h := husky.New()
+	auth := KeyAuth("query:api_key,cookie:api_key", apiKeys)
+
+	r, _ := http.NewRequest("GET", "/?api_key=key-1", nil)
+	w := httptest.NewRecorder()
+	auth(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "api_key", Value: "key-1"})
+	w = httptest.NewRecorder()
+	auth(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestKeyAuthRejectsInvalidKeys(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer key-2")
+	w := httptest.NewRecorder()
+
+	KeyAuth("header:Authorization:Bearer ", apiKeys)(principalHandler)(h.NewContext(w, r))
+
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Key realm="Restricted"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestKeyAuthPanicsOnInvalidLookup(t *testing.T) {
+	assert.Panics(t, func() {
+		KeyAuth("body:key", apiKeys)
+	})
+}
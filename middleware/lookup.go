@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/vetebase/husky"
+)
+
+// ErrLookupMissing is returned when a lookup finds no value in the request
+var ErrLookupMissing = errors.New("value is missing from request")
+
+// parseLookup creates a TokenParser for a lookup string in the format
+// "<source>:<name>", where source is header, query, form or cookie.
+// Several lookups are separated by commas and tried in order, a header
+// lookup may include a prefix to strip, e.g. "header:Authorization:Bearer ".
+func parseLookup(lookup string) (TokenParser, error) {
+	var parsers []TokenParser
+
+	for _, part := range strings.Split(lookup, ",") {
+		fields := strings.SplitN(strings.TrimSpace(part), ":", 3)
+		if len(fields) < 2 || fields[1] == "" {
+			return nil, errors.New("invalid lookup " + part)
+		}
+
+		source, name := fields[0], fields[1]
+		prefix := ""
+		if len(fields) == 3 {
+			prefix = fields[2]
+		}
+
+		switch source {
+		case "header":
+			parsers = append(parsers, fromHeader(name, prefix))
+		case "query":
+			parsers = append(parsers, fromQuery(name))
+		case "form":
+			parsers = append(parsers, fromForm(name))
+		case "cookie":
+			parsers = append(parsers, fromCookie(name))
+		default:
+			return nil, errors.New("unknown lookup source " + source)
+		}
+	}
+
+	return func(ctx *husky.CTX) (string, error) {
+		for _, parser := range parsers {
+			if value, err := parser(ctx); err == nil {
+				return value, nil
+			}
+		}
+
+		return "", ErrLookupMissing
+	}, nil
+}
+
+func fromHeader(name string, prefix string) TokenParser {
+	return func(ctx *husky.CTX) (string, error) {
+		value := ctx.GetHeader(name)
+		if len(value) <= len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+			return "", ErrLookupMissing
+		}
+
+		return value[len(prefix):], nil
+	}
+}
+
+func fromQuery(name string) TokenParser {
+	return func(ctx *husky.CTX) (string, error) {
+		value := ctx.Request.URL.Query().Get(name)
+		if value == "" {
+			return "", ErrLookupMissing
+		}
+
+		return value, nil
+	}
+}
+
+func fromForm(name string) TokenParser {
+	return func(ctx *husky.CTX) (string, error) {
+		value := ctx.FormValue(name)
+		if value == "" {
+			return "", ErrLookupMissing
+		}
+
+		return value, nil
+	}
+}
+
+func fromCookie(name string) TokenParser {
+	return func(ctx *husky.CTX) (string, error) {
+		cookie, err := ctx.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", ErrLookupMissing
+		}
+
+		return cookie.Value, nil
+	}
+}
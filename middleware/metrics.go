@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vetebase/husky"
+)
+
+// MetricsConfig configuration for Metrics middleware
+type MetricsConfig struct {
+	Registry  *husky.Metrics // defaults to the Metrics of the service
+	Namespace string         // prefix of the metric names, e.g. "billing"
+	Buckets   []float64      // latency buckets in seconds
+}
+
+// DefaultMetricsConfig handles the default Metrics configuration for Husky
+var DefaultMetricsConfig = MetricsConfig{
+	Buckets: husky.DefaultBuckets,
+}
+
+// UnmatchedRoute is the route label of requests matching no route
+const UnmatchedRoute = "unmatched"
+
+// Metrics middleware for Husky routes
+// Records request counts, latencies and in flight requests labeled by the
+// route pattern instead of the raw path to keep the number of series bounded
+func Metrics() func(next husky.Handler) husky.Handler {
+	return MetricsConfigured(DefaultMetricsConfig)
+}
+
+// MetricsConfigured returns a configured Metrics middleware
+func MetricsConfigured(config MetricsConfig) func(next husky.Handler) husky.Handler {
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultMetricsConfig.Buckets
+	}
+
+	prefix := ""
+	if config.Namespace != "" {
+		prefix = config.Namespace + "_"
+	}
+
+	// the metrics are resolved once per registry instead of on every request
+	var resolved sync.Map
+	resolve := func(registry *husky.Metrics) *httpMetrics {
+		if m, ok := resolved.Load(registry); ok {
+			return m.(*httpMetrics)
+		}
+
+		m, _ := resolved.LoadOrStore(registry, newHTTPMetrics(registry, prefix, config.Buckets))
+		return m.(*httpMetrics)
+	}
+
+	var configured *httpMetrics
+	if config.Registry != nil {
+		configured = resolve(config.Registry)
+	}
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			metrics := configured
+			if metrics == nil && ctx.Husky() != nil && ctx.Husky().Metrics != nil {
+				metrics = resolve(ctx.Husky().Metrics)
+			}
+
+			// contexts created without a service have nowhere to record to
+			if metrics == nil {
+				return next(ctx)
+			}
+
+			method, route := ctx.Request.Method, ctx.Path()
+			if route == "" {
+				route = UnmatchedRoute
+			}
+
+			metrics.inFlight.Inc(method, route)
+			defer metrics.inFlight.Dec(method, route)
+
+			start := time.Now()
+			err := next(ctx)
+
+			// the error response decides the recorded status
+			ctx.Error(err)
+
+			status := ctx.Response.Status
+			if status == 0 {
+				status = 200
+			}
+			if err != nil && !ctx.Response.Committed {
+				status = 500
+			}
+
+			metrics.requests.Inc(method, route, strconv.Itoa(status))
+			metrics.duration.Observe(time.Since(start).Seconds(), method, route, strconv.Itoa(status))
+
+			return err
+		}
+	}
+}
+
+// httpMetrics holds the metrics recorded by the Metrics middleware
+type httpMetrics struct {
+	requests *husky.CounterVec
+	duration *husky.HistogramVec
+	inFlight *husky.GaugeVec
+}
+
+func newHTTPMetrics(registry *husky.Metrics, prefix string, buckets []float64) *httpMetrics {
+	return &httpMetrics{
+		requests: registry.Counter(prefix+"http_requests_total", "Total number of HTTP requests.", "method", "route", "status"),
+		duration: registry.Histogram(prefix+"http_request_duration_seconds", "Duration of HTTP requests in seconds.", buckets, "method", "route", "status"),
+		inFlight: registry.Gauge(prefix+"http_requests_in_flight", "Number of HTTP requests being served.", "method", "route"),
+	}
+}
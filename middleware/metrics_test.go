@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func TestMetricsLabelsByRoutePattern(t *testing.T) {
+	h := husky.New()
+	h.Middlware(Metrics())
+	h.NotFound = Metrics()(husky.NotFoundHandler)
+	h.MetricsEndpoint("/metrics")
+
+	h.GET("/users/:id", func(ctx *husky.CTX) error {
+		if ctx.GetParam("id") == "0" {
+			return ctx.JSON(404, "Not Found")
+		}
+
+		return ctx.JSON(200, ctx.GetParam("id"))
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/missing/1", "/missing/2"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	r, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	body := w.Body.String()
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, body, "# TYPE http_requests_total counter\n")
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/users/:id",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_in_flight{method="GET",route="/users/:id"} 0`)
+	assert.NotContains(t, body, "/users/1")
+
+	// unmatched paths share one series
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 2`)
+	assert.NotContains(t, body, "/missing")
+}
+
+func TestMetricsConfiguredRegistry(t *testing.T) {
+	h := husky.New()
+	registry := husky.NewMetrics()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	MetricsConfigured(MetricsConfig{Registry: registry, Namespace: "billing"})(handler)(h.NewContext(httptest.NewRecorder(), r))
+
+	var b strings.Builder
+	registry.WritePrometheus(&b)
+
+	assert.Contains(t, b.String(), `billing_http_requests_total{method="GET",route="unmatched",status="200"} 1`)
+}
+
+func TestMetricsWithoutService(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	husky.ToHTTPHandler(Metrics()(func(ctx *husky.CTX) error {
+		return ctx.JSON(200, "ok")
+	})).ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vetebase/husky"
+)
+
+// SecureConfig configuration for Secure middleware
+// Empty fields are not sent, start from DefaultSecureConfig to keep the defaults
+type SecureConfig struct {
+	HSTSMaxAge            int      // Strict-Transport-Security max-age in seconds, 0 disables HSTS
+	HSTSIncludeSubdomains bool     // adds includeSubDomains to HSTS
+	HSTSPreload           bool     // adds preload to HSTS
+	ContentSecurityPolicy string   // "{nonce}" is replaced with a per request nonce
+	CSPReportOnly         bool     // sends Content-Security-Policy-Report-Only instead
+	FrameOptions          string   // X-Frame-Options, e.g. "DENY" or "SAMEORIGIN"
+	ContentTypeOptions    string   // X-Content-Type-Options, e.g. "nosniff"
+	ReferrerPolicy        string   // Referrer-Policy
+	PermissionsPolicy     string   // Permissions-Policy
+	HTTPSRedirect         bool     // redirects plain HTTP requests to HTTPS
+	TrustedProxies        []string // CIDRs or IPs allowed to set X-Forwarded-Proto
+}
+
+// DefaultSecureConfig handles the default Secure configuration for Husky
+var DefaultSecureConfig = SecureConfig{
+	HSTSMaxAge:            31536000,
+	HSTSIncludeSubdomains: true,
+	FrameOptions:          "SAMEORIGIN",
+	ContentTypeOptions:    "nosniff",
+	ReferrerPolicy:        "strict-origin-when-cross-origin",
+}
+
+// CSPNonceKey holds the Content-Security-Policy nonce of the request
+var CSPNonceKey = husky.NewKey[string]("csp nonce")
+
+// CSPNonce returns the Content-Security-Policy nonce generated for the request
+func CSPNonce(ctx *husky.CTX) string {
+	nonce, _ := CSPNonceKey.Get(ctx)
+	return nonce
+}
+
+// Secure middleware for Husky routes using the default configuration
+func Secure() func(next husky.Handler) husky.Handler {
+	return SecureConfigured(DefaultSecureConfig)
+}
+
+// SecureConfigured returns a configured Secure middleware
+// Panics if a trusted proxy is not a valid IP or CIDR
+func SecureConfigured(config SecureConfig) func(next husky.Handler) husky.Handler {
+	proxies := parseTrustedProxies(config.TrustedProxies)
+
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(config.HSTSMaxAge)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	cspHeader := "Content-Security-Policy"
+	if config.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	useNonce := strings.Contains(config.ContentSecurityPolicy, "{nonce}")
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			req := ctx.Request
+			secure := isHTTPS(req, proxies)
+
+			if config.HTTPSRedirect && !secure {
+				// 301 lets clients switch other methods to GET, 308 keeps them
+				code := http.StatusPermanentRedirect
+				if req.Method == "GET" || req.Method == "HEAD" {
+					code = http.StatusMovedPermanently
+				}
+
+				return ctx.Redirect(code, "https://"+req.Host+req.URL.RequestURI())
+			}
+
+			header := ctx.Response.Header()
+
+			if hsts != "" && secure {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+
+			if config.ContentSecurityPolicy != "" {
+				policy := config.ContentSecurityPolicy
+
+				if useNonce {
+					nonce, err := generateNonce()
+					if err != nil {
+						return ctx.JSON(http.StatusInternalServerError, "Secure Error")
+					}
+
+					CSPNonceKey.Set(ctx, nonce)
+					policy = strings.Replace(policy, "{nonce}", "'nonce-"+nonce+"'", -1)
+				}
+
+				header.Set(cspHeader, policy)
+			}
+
+			if config.FrameOptions != "" {
+				header.Set("X-Frame-Options", config.FrameOptions)
+			}
+
+			if config.ContentTypeOptions != "" {
+				header.Set("X-Content-Type-Options", config.ContentTypeOptions)
+			}
+
+			if config.ReferrerPolicy != "" {
+				header.Set("Referrer-Policy", config.ReferrerPolicy)
+			}
+
+			if config.PermissionsPolicy != "" {
+				header.Set("Permissions-Policy", config.PermissionsPolicy)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// generateNonce returns 128 random bits encoded as base64
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// parseTrustedProxies converts IPs and CIDRs into networks
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic("husky: invalid trusted proxy " + proxy)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// isHTTPS reports if the request was made over TLS, either directly or
+// through one of the trusted proxies
+func isHTTPS(req *http.Request, proxies []*net.IPNet) bool {
+	if req.TLS != nil {
+		return true
+	}
+
+	if !isTrustedProxy(req.RemoteAddr, proxies) {
+		return false
+	}
+
+	proto := strings.TrimSpace(strings.Split(req.Header.Get("X-Forwarded-Proto"), ",")[0])
+	return strings.EqualFold(proto, "https") || strings.EqualFold(req.Header.Get("X-Forwarded-Ssl"), "on")
+}
+
+// isTrustedProxy checks the remote address against the trusted proxies
+func isTrustedProxy(remoteAddr string, proxies []*net.IPNet) bool {
+	if len(proxies) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func handler(ctx *husky.CTX) error {
+	return nil
+}
+
+func TestSecureSetsDefaultHeaders(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	Secure()(handler)(h.NewContext(w, r))
+
+	assert.Equal(t, "SAMEORIGIN", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", w.Header().Get("Referrer-Policy"))
+
+	// HSTS is only sent over HTTPS
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecureTrustsProxyHeaders(t *testing.T) {
+	h := husky.New()
+
+	config := DefaultSecureConfig
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	secure := SecureConfigured(config)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.1.2.3:4567"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	secure(handler)(h.NewContext(w, r))
+
+	assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.168.1.1:4567"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	secure(handler)(h.NewContext(w, r))
+
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecureRedirectsToHTTPS(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "http://example.com/path?q=1", nil)
+	w := httptest.NewRecorder()
+
+	SecureConfigured(SecureConfig{HTTPSRedirect: true})(handler)(h.NewContext(w, r))
+
+	assert.Equal(t, 301, w.Code)
+	assert.Equal(t, "https://example.com/path?q=1", w.Header().Get("Location"))
+
+	// other methods keep their method and body
+	r, _ = http.NewRequest("POST", "http://example.com/path", nil)
+	w = httptest.NewRecorder()
+
+	SecureConfigured(SecureConfig{HTTPSRedirect: true})(handler)(h.NewContext(w, r))
+
+	assert.Equal(t, 308, w.Code)
+	assert.Equal(t, "https://example.com/path", w.Header().Get("Location"))
+}
+
+func TestSecureGeneratesCSPNonce(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	var nonce string
+	SecureConfigured(SecureConfig{
+		ContentSecurityPolicy: "script-src {nonce}",
+		CSPReportOnly:         true,
+	})(func(ctx *husky.CTX) error {
+		nonce = CSPNonce(ctx)
+		return nil
+	})(h.NewContext(w, r))
+
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, "script-src 'nonce-"+nonce+"'", w.Header().Get("Content-Security-Policy-Report-Only"))
+	assert.False(t, strings.Contains(w.Header().Get("Content-Security-Policy-Report-Only"), "{nonce}"))
+}
@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vetebase/husky"
+)
+
+// TimeoutConfig configuration for Timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration // maximum duration of the handler
+	Message string        // JSON message sent with the 503 response
+}
+
+// DefaultTimeoutConfig handles the default Timeout configuration for Husky
+var DefaultTimeoutConfig = TimeoutConfig{
+	Timeout: 30 * time.Second,
+	Message: "Service Unavailable",
+}
+
+type timeoutKey struct{}
+
+// Timeout middleware for Husky routes
+// The deadline is available to handlers through ctx.Context(). A Timeout
+// added to a route replaces a Timeout added with h.Middlware for that route,
+// so slow routes can be given a longer deadline than the global default.
+// Upgrade requests such as WebSockets are not bounded.
+func Timeout(timeout time.Duration) func(next husky.Handler) husky.Handler {
+	return TimeoutConfigured(TimeoutConfig{Timeout: timeout})
+}
+
+// TimeoutConfigured returns a configured Timeout middleware
+func TimeoutConfigured(config TimeoutConfig) func(next husky.Handler) husky.Handler {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeoutConfig.Timeout
+	}
+
+	if config.Message == "" {
+		config.Message = DefaultTimeoutConfig.Message
+	}
+
+	message, _ := json.Marshal(config.Message)
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			// upgraded connections are hijacked and outlive any timeout
+			if ctx.GetHeader("Upgrade") != "" {
+				return next(ctx)
+			}
+
+			// an outer timeout is already running, replace its deadline
+			if scope, ok := ctx.Context().Value(timeoutKey{}).(*timeoutScope); ok {
+				scope.derive(ctx, config.Timeout)
+				return next(ctx)
+			}
+
+			scope := &timeoutScope{parent: ctx.Context(), reset: make(chan struct{}, 1)}
+			scope.derive(ctx, config.Timeout)
+			defer scope.cancel()
+
+			// buffer the response so a timeout never leaves it half written
+			original := ctx.Response.Writer
+			writer := &timeoutWriter{dst: original, header: original.Header().Clone()}
+			ctx.Response.Writer = writer
+
+			// the handler may outlive the request, keep its resources until it exits
+			release := ctx.Hold()
+
+			done := make(chan error, 1)
+			panicked := make(chan interface{}, 1)
+
+			go func() {
+				defer release()
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+
+				done <- next(ctx)
+			}()
+
+			timer := time.NewTimer(time.Until(scope.expires()))
+			defer func() {
+				timer.Stop()
+			}()
+
+			for {
+				select {
+				case err := <-done:
+					ctx.Response.Writer = original
+					writer.flushTo(original)
+					return err
+				case p := <-panicked:
+					ctx.Response.Writer = original
+					panic(p)
+				case <-scope.reset:
+					timer.Stop()
+					timer = time.NewTimer(time.Until(scope.expires()))
+				case <-timer.C:
+					scope.cancel()
+
+					// a flushed response already reached the client
+					var write func(response *husky.Response)
+					if !writer.timeout() {
+						write = func(response *husky.Response) {
+							response.Header().Set("Content-Type", "application/json")
+							response.WriteHeader(http.StatusServiceUnavailable)
+							response.Write(message)
+						}
+					}
+
+					ctx.Response.Interrupt(original, http.ErrHandlerTimeout, write)
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// timeoutScope tracks the deadline of the outermost Timeout middleware so
+// route level timeouts can replace it
+type timeoutScope struct {
+	parent   context.Context
+	reset    chan struct{}
+	mu       sync.Mutex
+	deadline time.Time
+	cancels  []context.CancelFunc
+}
+
+// derive sets a deadline context derived from the context the outermost
+// Timeout started with, values set by earlier middleware are kept
+func (scope *timeoutScope) derive(ctx *husky.CTX, timeout time.Duration) {
+	c, cancel := context.WithTimeout(scope.parent, timeout)
+	deadline, _ := c.Deadline()
+
+	scope.mu.Lock()
+	scope.cancels = append(scope.cancels, cancel)
+	scope.deadline = deadline
+	scope.mu.Unlock()
+
+	if current := ctx.Context(); current != scope.parent {
+		c = valuesContext{Context: c, values: current}
+	}
+
+	ctx.SetContext(context.WithValue(c, timeoutKey{}, scope))
+
+	select {
+	case scope.reset <- struct{}{}:
+	default:
+	}
+}
+
+// valuesContext takes its deadline from the embedded context and its values
+// from the context it replaces
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+// expires returns the current deadline
+func (scope *timeoutScope) expires() time.Time {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	return scope.deadline
+}
+
+// cancel cancels every deadline context created for the request
+func (scope *timeoutScope) cancel() {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	for _, cancel := range scope.cancels {
+		cancel()
+	}
+}
+
+// timeoutWriter buffers the handler response until it completes, a flushed
+// or hijacked response is written through to the client
+type timeoutWriter struct {
+	mu       sync.Mutex
+	dst      http.ResponseWriter
+	header   http.Header
+	buffer   bytes.Buffer
+	code     int
+	flushed  bool
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	if w.flushed {
+		return w.dst.Write(b)
+	}
+
+	return w.buffer.Write(b)
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.code != 0 {
+		return
+	}
+
+	w.code = code
+}
+
+// Flush sends the buffered response and streams later writes, implements
+// http.Flusher for ctx.SSE()
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return
+	}
+
+	w.writeBuffer()
+
+	if flusher, ok := w.dst.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes the connection of the client through, implements http.Hijacker
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	hijacker, ok := w.dst.(http.Hijacker)
+	if !ok {
+		return nil, nil, husky.ErrHijackUnsupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.flushed = true
+	}
+
+	return conn, rw, err
+}
+
+// timeout rejects any further writes from the handler and reports if the
+// response was already flushed
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.timedOut = true
+	return w.flushed
+}
+
+// flushTo copies the buffered response to the client
+func (w *timeoutWriter) flushTo(dst http.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeBuffer()
+}
+
+// writeBuffer sends the header and buffer to dst once
+func (w *timeoutWriter) writeBuffer() {
+	if w.flushed {
+		return
+	}
+	w.flushed = true
+
+	header := w.dst.Header()
+	for k := range header {
+		delete(header, k)
+	}
+	for k, v := range w.header {
+		header[k] = v
+	}
+
+	if w.code != 0 {
+		w.dst.WriteHeader(w.code)
+	}
+
+	w.dst.Write(w.buffer.Bytes())
+	w.buffer.Reset()
+}
@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+func sleepHandler(d time.Duration) husky.Handler {
+	return func(ctx *husky.CTX) error {
+		select {
+		case <-time.After(d):
+			return ctx.JSON(200, "done")
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+	}
+}
+
+func TestTimeoutAllowsFastHandlers(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := Timeout(time.Second)(sleepHandler(0))(h.NewContext(w, r))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `"done"`, w.Body.String())
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestTimeoutReturnsServiceUnavailable(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := Timeout(10 * time.Millisecond)(sleepHandler(time.Second))(h.NewContext(w, r))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 503, w.Code)
+		assert.Equal(t, `"Service Unavailable"`, w.Body.String())
+	}
+}
+
+func TestTimeoutExposesDeadline(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	Timeout(time.Second)(func(ctx *husky.CTX) error {
+		_, ok := ctx.Context().Deadline()
+		assert.True(t, ok)
+		return nil
+	})(h.NewContext(w, r))
+}
+
+func TestRouteTimeoutReplacesGlobalTimeout(t *testing.T) {
+	h := husky.New()
+	h.Middlware(Timeout(50 * time.Millisecond))
+
+	h.GET("/short", sleepHandler(20*time.Millisecond), Timeout(10*time.Millisecond))
+	h.GET("/long", sleepHandler(100*time.Millisecond), Timeout(time.Second))
+	h.GET("/default", sleepHandler(100*time.Millisecond))
+
+	r, _ := http.NewRequest("GET", "/short", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 503, w.Code)
+
+	// a longer route timeout wins over the global default
+	r, _ = http.NewRequest("GET", "/long", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+
+	r, _ = http.NewRequest("GET", "/default", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 503, w.Code)
+}
+
+func TestRouteTimeoutKeepsContextValues(t *testing.T) {
+	key := husky.NewKey[string]("tenant")
+
+	h := husky.New()
+	h.Middlware(func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			key.Set(ctx, "acme")
+			return next(ctx)
+		}
+	})
+	h.Middlware(Timeout(time.Second))
+
+	h.GET("/", func(ctx *husky.CTX) error {
+		tenant, _ := key.Get(ctx)
+		return ctx.JSON(200, tenant)
+	}, Timeout(100*time.Millisecond))
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"acme"`, w.Body.String())
+}
+
+func TestTimeoutRecordsServiceUnavailable(t *testing.T) {
+	h := husky.New()
+	release := make(chan struct{})
+	finished := make(chan error)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	ctx := h.NewContext(w, r)
+
+	Timeout(10 * time.Millisecond)(func(ctx *husky.CTX) error {
+		<-release
+		err := ctx.JSON(200, "late")
+		finished <- err
+		return err
+	})(ctx)
+
+	assert.Equal(t, 503, ctx.Response.Status)
+	assert.True(t, ctx.Response.Committed)
+
+	// writes of the handler after the timeout are rejected
+	close(release)
+	assert.Equal(t, http.ErrHandlerTimeout, <-finished)
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, `"Service Unavailable"`, w.Body.String())
+	assert.Equal(t, 503, ctx.Response.Status)
+}
+
+type timeoutResource struct {
+	closed chan struct{}
+}
+
+func (resource *timeoutResource) Close() error {
+	close(resource.closed)
+	return nil
+}
+
+func TestTimeoutDelaysCleanup(t *testing.T) {
+	resource := &timeoutResource{closed: make(chan struct{})}
+	release := make(chan struct{})
+
+	h := husky.New()
+	husky.Provide[*timeoutResource](h, husky.LifetimeRequest, func() *timeoutResource {
+		return resource
+	})
+	h.Middlware(Timeout(10 * time.Millisecond))
+	h.GET("/", func(ctx *husky.CTX) error {
+		husky.MustResolve[*timeoutResource](ctx)
+		<-release
+		return nil
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 503, w.Code)
+
+	select {
+	case <-resource.closed:
+		t.Fatal("resource closed while the handler is running")
+	default:
+	}
+
+	close(release)
+
+	select {
+	case <-resource.closed:
+	case <-time.After(time.Second):
+		t.Fatal("resource not closed after the handler returned")
+	}
+}
+
+func TestTimeoutStreamsEvents(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := Timeout(50 * time.Millisecond)(func(ctx *husky.CTX) error {
+		stream, err := ctx.SSE()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		stream.Send(husky.Event{Data: "tick"})
+		<-ctx.Context().Done()
+		return nil
+	})(h.NewContext(w, r))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "data: tick\n\n")
+	}
+}
+
+func TestTimeoutSkipsUpgradeRequests(t *testing.T) {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Upgrade", "websocket")
+	w := httptest.NewRecorder()
+
+	Timeout(10 * time.Millisecond)(func(ctx *husky.CTX) error {
+		_, ok := ctx.Context().Deadline()
+		assert.False(t, ok)
+		assert.Equal(t, w, ctx.Response.Writer)
+		return nil
+	})(h.NewContext(w, r))
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/vetebase/husky"
+	"github.com/vetebase/husky/trace"
+)
+
+// TracingConfig configuration for Tracing middleware
+type TracingConfig struct {
+	Tracer *trace.Tracer
+}
+
+// Tracing middleware for Husky routes
+// Continues the trace of the W3C traceparent header or starts a new one, the
+// server span is available to handlers through trace.SpanFromContext(ctx.Context()).
+// Spans are passed to the exporter as they finish, wrap it with
+// trace.NewBatchExporter to export them in the background instead.
+func Tracing(exporter trace.Exporter) func(next husky.Handler) husky.Handler {
+	return TracingConfigured(TracingConfig{Tracer: trace.NewTracer(exporter)})
+}
+
+// TracingConfigured returns a configured Tracing middleware
+// Panics if no tracer is set
+func TracingConfigured(config TracingConfig) func(next husky.Handler) husky.Handler {
+	if config.Tracer == nil {
+		panic("husky: tracing middleware requires a tracer")
+	}
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			c := ctx.Context()
+			if parent, ok := trace.Extract(ctx.Request.Header); ok {
+				c = trace.ContextWithRemoteSpanContext(c, parent)
+			}
+
+			// requests matching no route are named by their method alone
+			name, route := ctx.Request.Method, ctx.Path()
+			if route != "" {
+				name += " " + route
+			}
+
+			c, span := config.Tracer.Start(c, name, trace.SpanKindServer)
+			defer span.Finish()
+
+			span.SetAttribute("http.request.method", ctx.Request.Method)
+			if route != "" {
+				span.SetAttribute("http.route", route)
+			}
+			span.SetAttribute("url.path", ctx.Request.URL.Path)
+			if agent := ctx.Request.UserAgent(); agent != "" {
+				span.SetAttribute("user_agent.original", agent)
+			}
+
+			ctx.SetContext(c)
+			err := next(ctx)
+
+			// the span reports the status of the error response
+			ctx.Error(err)
+
+			status := ctx.Response.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttribute("http.response.status_code", status)
+
+			if err != nil {
+				span.RecordError(err)
+			} else if status >= 500 {
+				span.SetStatus(trace.StatusError, http.StatusText(status))
+			}
+
+			return err
+		}
+	}
+}
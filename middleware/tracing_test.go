@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+	"github.com/vetebase/husky/trace"
+)
+
+func TestTracingContinuesTraceparent(t *testing.T) {
+	exporter := trace.NewInMemoryExporter()
+
+	h := husky.New()
+	h.Middlware(Tracing(exporter))
+
+	var outgoing http.Header
+	h.GET("/users/:id", func(ctx *husky.CTX) error {
+		outgoing = http.Header{}
+		trace.Inject(ctx.Context(), outgoing)
+
+		return ctx.JSON(200, ctx.GetParam("id"))
+	})
+
+	r, _ := http.NewRequest("GET", "/users/5", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	span := spans[0]
+	assert.Equal(t, "GET /users/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID.String())
+	assert.Equal(t, "/users/:id", span.Attributes["http.route"])
+	assert.Equal(t, 200, span.Attributes["http.response.status_code"])
+	assert.Equal(t, trace.StatusUnset, span.Status)
+	assert.Equal(t, span.SpanContext.Traceparent(), outgoing.Get("traceparent"))
+}
+
+func TestTracingRecordsErrors(t *testing.T) {
+	exporter := trace.NewInMemoryExporter()
+
+	h := husky.New()
+	h.GET("/fail", func(ctx *husky.CTX) error {
+		return errors.New("database down")
+	}, Tracing(exporter))
+
+	r, _ := http.NewRequest("GET", "/fail", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 500, w.Code)
+
+	span := exporter.Spans()[0]
+	assert.Equal(t, 500, span.Attributes["http.response.status_code"])
+	assert.Equal(t, trace.StatusError, span.Status)
+	assert.Equal(t, "database down", span.StatusMessage)
+	assert.Equal(t, "exception", span.Events[0].Name)
+}
+
+func TestTracingNamesUnmatchedRequests(t *testing.T) {
+	exporter := trace.NewInMemoryExporter()
+
+	h := husky.New()
+	h.NotFound = Tracing(exporter)(husky.NotFoundHandler)
+
+	r, _ := http.NewRequest("GET", "/missing", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := exporter.Spans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "GET", spans[0].Name)
+		assert.NotContains(t, spans[0].Attributes, "http.route")
+		assert.Equal(t, 404, spans[0].Attributes["http.response.status_code"])
+	}
+}
@@ -0,0 +1,311 @@
+package husky
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// ErrFileTooLarge is returned when a file exceeds MultipartConfig.MaxFileSize
+var ErrFileTooLarge = errors.New("husky: multipart file too large")
+
+// ErrMultipartTooLarge is returned when the parts exceed MultipartConfig.MaxTotalSize
+var ErrMultipartTooLarge = errors.New("husky: multipart body too large")
+
+// ErrFileTypeNotAllowed is returned when the sniffed type of a file is not
+// one of MultipartConfig.AllowedTypes
+var ErrFileTypeNotAllowed = errors.New("husky: multipart file type not allowed")
+
+// sniffLen is the number of bytes used by http.DetectContentType
+const sniffLen = 512
+
+// MultipartConfig configuration for multipart uploads
+type MultipartConfig struct {
+	MaxFileSize  int64    // maximum size of a single file
+	MaxTotalSize int64    // maximum size of all parts together
+	MaxMemory    int64    // file bytes kept in memory before spilling to temp files
+	TempDir      string   // directory of spilled files, defaults to os.TempDir()
+	AllowedTypes []string // sniffed content types accepted for files, empty allows all
+}
+
+// DefaultMultipartConfig handles the default multipart configuration for Husky
+var DefaultMultipartConfig = MultipartConfig{
+	MaxFileSize:  32 << 20,
+	MaxTotalSize: 64 << 20,
+	MaxMemory:    1 << 20,
+}
+
+// MultipartForm is a parsed multipart form
+type MultipartForm struct {
+	Value map[string][]string
+	File  map[string][]*FileHeader
+}
+
+// FileHeader describes an uploaded file
+type FileHeader struct {
+	Filename    string
+	Header      textproto.MIMEHeader
+	Size        int64
+	ContentType string // sniffed from the content, not the client supplied header
+	content     []byte
+	tmpfile     string
+}
+
+// Open returns the content of the file
+func (f *FileHeader) Open() (multipart.File, error) {
+	if f.tmpfile != "" {
+		return os.Open(f.tmpfile)
+	}
+
+	return memoryFile{io.NewSectionReader(bytes.NewReader(f.content), 0, int64(len(f.content)))}, nil
+}
+
+type memoryFile struct {
+	*io.SectionReader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// MultipartReader streams the parts of a multipart request body
+type MultipartReader struct {
+	reader *multipart.Reader
+	config MultipartConfig
+	total  int64
+}
+
+// Part is a single part of a multipart body, reads are limited by the
+// configured sizes
+type Part struct {
+	*multipart.Part
+	ContentType string // sniffed content type of file parts
+	reader      io.Reader
+}
+
+// Read reads the content of the part
+func (p *Part) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// MultipartReader returns a streaming reader of the multipart body, use it
+// instead of MultipartForm to process large uploads without buffering them
+func (ctx *CTX) MultipartReader() (*MultipartReader, error) {
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultipartReader{reader: reader, config: ctx.multipartConfig()}, nil
+}
+
+// NextPart returns the next part or io.EOF when all parts were read
+func (r *MultipartReader) NextPart() (*Part, error) {
+	part, err := r.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	limited := &partReader{src: part, reader: r}
+	if part.FileName() != "" {
+		limited.max = r.config.MaxFileSize
+	}
+
+	p := &Part{Part: part, reader: limited}
+	if part.FileName() == "" {
+		return p, nil
+	}
+
+	buffered := bufio.NewReaderSize(limited, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	p.ContentType = http.DetectContentType(head)
+	p.reader = buffered
+
+	if !allowedType(p.ContentType, r.config.AllowedTypes) {
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	return p, nil
+}
+
+// partReader enforces the per file and total size limits
+type partReader struct {
+	src    io.Reader
+	reader *MultipartReader
+	read   int64
+	max    int64
+}
+
+func (p *partReader) Read(b []byte) (int, error) {
+	n, err := p.src.Read(b)
+	p.read += int64(n)
+	p.reader.total += int64(n)
+
+	if p.max > 0 && p.read > p.max {
+		return 0, ErrFileTooLarge
+	}
+
+	if max := p.reader.config.MaxTotalSize; max > 0 && p.reader.total > max {
+		return 0, ErrMultipartTooLarge
+	}
+
+	return n, err
+}
+
+// MultipartForm parses the multipart body, files larger than MaxMemory are
+// spilled to temp files which are removed after the request
+func (ctx *CTX) MultipartForm() (*MultipartForm, error) {
+	if ctx.multipartForm != nil {
+		return ctx.multipartForm, nil
+	}
+
+	reader, err := ctx.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	form := &MultipartForm{
+		Value: make(map[string][]string),
+		File:  make(map[string][]*FileHeader),
+	}
+	memory := reader.config.MaxMemory
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			var b strings.Builder
+			if _, err := io.Copy(&b, part); err != nil {
+				return nil, err
+			}
+			form.Value[name] = append(form.Value[name], b.String())
+			continue
+		}
+
+		file := &FileHeader{
+			Filename:    part.FileName(),
+			Header:      part.Header,
+			ContentType: part.ContentType,
+		}
+
+		if err := ctx.readFile(file, part, &memory, reader.config.TempDir); err != nil {
+			return nil, err
+		}
+
+		form.File[name] = append(form.File[name], file)
+	}
+
+	ctx.multipartForm = form
+	return form, nil
+}
+
+// FormFile returns the first file uploaded as name
+func (ctx *CTX) FormFile(name string) (*FileHeader, error) {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	if files := form.File[name]; len(files) > 0 {
+		return files[0], nil
+	}
+
+	return nil, http.ErrMissingFile
+}
+
+// readFile keeps the part in memory while the memory budget allows it and
+// spills it to a temp file otherwise
+func (ctx *CTX) readFile(file *FileHeader, part io.Reader, memory *int64, dir string) error {
+	var b bytes.Buffer
+
+	n, err := io.CopyN(&b, part, *memory+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	if n <= *memory {
+		*memory -= n
+		file.content = b.Bytes()
+		file.Size = n
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(dir, "husky-multipart-")
+	if err != nil {
+		return err
+	}
+	ctx.OnCleanup(func() {
+		os.Remove(tmp.Name())
+	})
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.MultiReader(&b, part))
+	if err != nil {
+		return err
+	}
+
+	file.tmpfile = tmp.Name()
+	file.Size = size
+	return nil
+}
+
+// multipartConfig returns the multipart configuration of the Husky service
+// with defaults for empty values
+func (ctx *CTX) multipartConfig() MultipartConfig {
+	config := DefaultMultipartConfig
+	if ctx.husky != nil {
+		config = ctx.husky.Multipart
+	}
+
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = DefaultMultipartConfig.MaxFileSize
+	}
+
+	if config.MaxTotalSize == 0 {
+		config.MaxTotalSize = DefaultMultipartConfig.MaxTotalSize
+	}
+
+	if config.MaxMemory == 0 {
+		config.MaxMemory = DefaultMultipartConfig.MaxMemory
+	}
+
+	return config
+}
+
+// allowedType reports if the content type is one of the allowed types, a
+// type ending in "/*" allows every subtype
+func allowedType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	for _, a := range allowed {
+		if a == mediaType || (strings.HasSuffix(a, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package husky
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMultipartRequest builds a multipart body with the values and files,
+// files maps the form name to the file content
+func newMultipartRequest(values map[string]string, files map[string]string) (string, *bytes.Buffer) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for k, v := range values {
+		w.WriteField(k, v)
+	}
+
+	for k, v := range files {
+		f, _ := w.CreateFormFile(k, k+".txt")
+		f.Write([]byte(v))
+	}
+	w.Close()
+
+	return w.FormDataContentType(), &body
+}
+
+func TestMultipartForm(t *testing.T) {
+	h := New()
+
+	contentType, body := newMultipartRequest(map[string]string{"title": "report"}, map[string]string{"upload": "hello world"})
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+	c := h.NewContext(httptest.NewRecorder(), r)
+
+	form, err := c.MultipartForm()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"report"}, form.Value["title"])
+
+	file, err := c.FormFile("upload")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "upload.txt", file.Filename)
+	assert.Equal(t, int64(11), file.Size)
+	assert.Equal(t, "text/plain; charset=utf-8", file.ContentType)
+
+	f, _ := file.Open()
+	b, _ := io.ReadAll(f)
+	assert.Equal(t, "hello world", string(b))
+
+	_, err = c.FormFile("missing")
+	assert.Error(t, err)
+}
+
+func TestMultipartSpillsToTempFile(t *testing.T) {
+	h := New()
+	h.Multipart.MaxMemory = 4
+	h.Multipart.TempDir = t.TempDir()
+
+	var tmpfile string
+	h.POST("/upload", func(ctx *CTX) error {
+		file, err := ctx.FormFile("upload")
+		if err != nil {
+			return err
+		}
+		tmpfile = file.tmpfile
+
+		f, _ := file.Open()
+		defer f.Close()
+		b, _ := io.ReadAll(f)
+
+		return ctx.String(200, string(b))
+	})
+
+	contentType, body := newMultipartRequest(nil, map[string]string{"upload": "spilled to disk"})
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "spilled to disk", w.Body.String())
+	assert.NotEmpty(t, tmpfile)
+
+	// the temp file is removed after the request
+	_, err := os.Stat(tmpfile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMultipartLimits(t *testing.T) {
+	h := New()
+	h.Multipart.MaxFileSize = 4
+
+	contentType, body := newMultipartRequest(nil, map[string]string{"upload": "too large"})
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+
+	_, err := h.NewContext(httptest.NewRecorder(), r).MultipartForm()
+	assert.Equal(t, ErrFileTooLarge, err)
+
+	h = New()
+	h.Multipart.MaxTotalSize = 10
+
+	contentType, body = newMultipartRequest(map[string]string{"a": "123456", "b": "123456"}, nil)
+	r = httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+
+	_, err = h.NewContext(httptest.NewRecorder(), r).MultipartForm()
+	assert.Equal(t, ErrMultipartTooLarge, err)
+}
+
+func TestMultipartAllowedTypes(t *testing.T) {
+	h := New()
+	h.Multipart.AllowedTypes = []string{"image/*"}
+
+	contentType, body := newMultipartRequest(nil, map[string]string{"avatar": "<html><body>not an image"})
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+
+	_, err := h.NewContext(httptest.NewRecorder(), r).FormFile("avatar")
+	assert.Equal(t, ErrFileTypeNotAllowed, err)
+
+	assert.True(t, allowedType("image/png", []string{"image/*"}))
+	assert.True(t, allowedType("text/plain; charset=utf-8", []string{"text/plain"}))
+	assert.False(t, allowedType("text/html; charset=utf-8", []string{"text/plain"}))
+}
+
+func TestMultipartReader(t *testing.T) {
+	h := New()
+
+	contentType, body := newMultipartRequest(nil, map[string]string{"upload": strings.Repeat("x", 1000)})
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+
+	reader, err := h.NewContext(httptest.NewRecorder(), r).MultipartReader()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	part, err := reader.NextPart()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "upload", part.FormName())
+	assert.Equal(t, "text/plain; charset=utf-8", part.ContentType)
+
+	b, err := io.ReadAll(part)
+	assert.NoError(t, err)
+	assert.Len(t, b, 1000)
+
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,152 @@
+package husky
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Offer is one representation of a response available for content negotiation
+type Offer struct {
+	ContentType string
+	Data        interface{}
+}
+
+// mediaRange is a parsed element of the Accept header
+type mediaRange struct {
+	value string
+	q     float64
+}
+
+// Accepts returns the offered content type best matching the Accept header,
+// or an empty string if none is acceptable
+func (ctx *CTX) Accepts(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	ranges := parseAccept(ctx.GetHeader("Accept"))
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(offer, ranges); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// Negotiate renders the offer best matching the Accept header, JSON, XML and
+// text offers are rendered with the matching renderer. HTML offers are escaped
+// unless the data is a template.HTML. Responds with 406 Not Acceptable if none
+// of the offers is accepted.
+func (ctx *CTX) Negotiate(code int, offers ...Offer) error {
+	// caches must key the response on the Accept header
+	ctx.Response.Header().Add("Vary", "Accept")
+
+	types := make([]string, len(offers))
+	for i, offer := range offers {
+		types[i] = offer.ContentType
+	}
+
+	accepted := ctx.Accepts(types...)
+	if accepted == "" {
+		return ctx.HTTPError(http.StatusNotAcceptable, "Not Acceptable")
+	}
+
+	for _, offer := range offers {
+		if offer.ContentType == accepted {
+			return ctx.render(code, offer)
+		}
+	}
+
+	return nil
+}
+
+// render writes the offer with the renderer for its content type
+func (ctx *CTX) render(code int, offer Offer) error {
+	switch offer.ContentType {
+	case MIMEApplicationJSON:
+		return ctx.JSON(code, offer.Data)
+	case MIMEApplicationXML, MIMETextXML:
+		return ctx.XML(code, offer.Data)
+	case MIMETextHTML:
+		if trusted, ok := offer.Data.(template.HTML); ok {
+			return ctx.String(code, string(trusted))
+		}
+		return ctx.String(code, html.EscapeString(fmt.Sprint(offer.Data)))
+	}
+
+	if b, ok := offer.Data.([]byte); ok {
+		return ctx.Blob(code, offer.ContentType, b)
+	}
+
+	contentType := offer.ContentType
+	if strings.HasPrefix(contentType, "text/") {
+		contentType += charsetUTF8
+	}
+
+	return ctx.Blob(code, contentType, []byte(fmt.Sprint(offer.Data)))
+}
+
+// parseAccept parses the media ranges and q-values of an Accept header
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = parsed
+				} else {
+					q = 0
+				}
+			}
+		}
+
+		ranges = append(ranges, mediaRange{value: value, q: q})
+	}
+
+	return ranges
+}
+
+// quality returns the q-value of the most specific range matching the
+// content type, 0 if no range matches
+func quality(contentType string, ranges []mediaRange) float64 {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	major := strings.Split(contentType, "/")[0]
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.value == contentType:
+			s = 2
+		case r.value == major+"/*":
+			s = 1
+		case r.value == "*/*" || r.value == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,336 @@
+package husky
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIVersion is the version of generated OpenAPI documents
+const OpenAPIVersion = "3.1.0"
+
+// RouteDoc documents a route in the OpenAPI document, it is returned when
+// adding a route
+//
+//	h.POST("/users", create).
+//		Summary("Create a user").
+//		Tags("users").
+//		Body(CreateUser{}).
+//		Response(201, User{}, "Created")
+type RouteDoc struct {
+	summary     string
+	description string
+	operationID string
+	tags        []string
+	deprecated  bool
+	hidden      bool
+	params      []ParamDoc
+	body        interface{}
+	responses   map[int]responseDoc
+}
+
+// ParamDoc documents a path, query, header or cookie param
+type ParamDoc struct {
+	In          string      // path, query, header or cookie
+	Name        string      // name of the param, :params and * are path params
+	Description string      // description of the param
+	Required    bool        // always true for path params
+	Type        interface{} // value of the param type, e.g. 0, nil for strings
+}
+
+type responseDoc struct {
+	body        interface{}
+	description string
+}
+
+// Summary sets the short summary of the route
+func (doc *RouteDoc) Summary(summary string) *RouteDoc {
+	doc.summary = summary
+	return doc
+}
+
+// Description sets the long description of the route
+func (doc *RouteDoc) Description(description string) *RouteDoc {
+	doc.description = description
+	return doc
+}
+
+// OperationID sets the unique id of the route, used by client generators
+func (doc *RouteDoc) OperationID(id string) *RouteDoc {
+	doc.operationID = id
+	return doc
+}
+
+// Tags adds tags grouping the route
+func (doc *RouteDoc) Tags(tags ...string) *RouteDoc {
+	doc.tags = append(doc.tags, tags...)
+	return doc
+}
+
+// Deprecated marks the route as deprecated
+func (doc *RouteDoc) Deprecated() *RouteDoc {
+	doc.deprecated = true
+	return doc
+}
+
+// Hidden leaves the route out of the document
+func (doc *RouteDoc) Hidden() *RouteDoc {
+	doc.hidden = true
+	return doc
+}
+
+// Param documents a param, path params of the endpoint are documented as
+// strings by default
+func (doc *RouteDoc) Param(param ParamDoc) *RouteDoc {
+	doc.params = append(doc.params, param)
+	return doc
+}
+
+// Query documents an optional query param of the type of v
+func (doc *RouteDoc) Query(name string, v interface{}, description string) *RouteDoc {
+	return doc.Param(ParamDoc{In: "query", Name: name, Description: description, Type: v})
+}
+
+// Body documents the JSON request body with the type of v
+func (doc *RouteDoc) Body(v interface{}) *RouteDoc {
+	doc.body = v
+	return doc
+}
+
+// Response documents the JSON response with the type of v for the status
+// code, nil for responses without a body. An empty description uses the
+// status text.
+func (doc *RouteDoc) Response(code int, v interface{}, description string) *RouteDoc {
+	if doc.responses == nil {
+		doc.responses = make(map[int]responseDoc)
+	}
+
+	if description == "" {
+		description = http.StatusText(code)
+	}
+
+	doc.responses[code] = responseDoc{body: v, description: description}
+	return doc
+}
+
+// OpenAPIInfo describes the service in the OpenAPI document
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIDocument is an OpenAPI 3.1 document
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIComponents holds the schemas referenced by the operations
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// OpenAPIOperation documents a route
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses,omitempty"`
+}
+
+// OpenAPIParameter documents a param of an operation
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// OpenAPIRequestBody documents the body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse documents a response of an operation
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a body
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var pathParamRe = regexp.MustCompile(`:` + pattern + `|\*`)
+
+// OpenAPI generates the OpenAPI document of all routes, including groups
+// Struct types used in bodies and params are added as components.
+func (husky *Husky) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	if info.Title == "" {
+		info.Title = "Husky"
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+
+	document := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	// sorted so schema names are stable when types share a name
+	var routes []Route
+	for _, endpoints := range husky.Router.Routes {
+		for _, route := range endpoints {
+			if route.Doc == nil || !route.Doc.hidden {
+				routes = append(routes, route)
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Endpoint != routes[j].Endpoint {
+			return routes[i].Endpoint < routes[j].Endpoint
+		}
+		return routes[i].Verb < routes[j].Verb
+	})
+
+	schemas := newSchemaGenerator()
+	for _, route := range routes {
+		doc := route.Doc
+		if doc == nil {
+			doc = &RouteDoc{}
+		}
+
+		path, params := openAPIPath(route.Endpoint)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+
+		document.Paths[path][strings.ToLower(route.Verb)] = doc.operation(params, schemas)
+	}
+
+	if len(schemas.schemas) > 0 {
+		document.Components = &OpenAPIComponents{Schemas: schemas.schemas}
+	}
+
+	return document
+}
+
+// OpenAPIEndpoint serves the OpenAPI document as JSON, the endpoint itself is
+// left out of the document
+func (husky *Husky) OpenAPIEndpoint(endpoint string, info OpenAPIInfo, middleware ...MiddlewareHandler) {
+	husky.add("GET", endpoint, func(ctx *CTX) error {
+		return ctx.JSON(200, husky.OpenAPI(info))
+	}, middleware).Hidden()
+}
+
+// openAPIPath converts the :params and * wildcard of an endpoint to OpenAPI
+// path templates, the * wildcard is named wildcard
+func openAPIPath(endpoint string) (string, []string) {
+	var params []string
+
+	path := pathParamRe.ReplaceAllStringFunc(endpoint, func(param string) string {
+		name := strings.TrimPrefix(param, ":")
+		if name == "*" {
+			name = "wildcard"
+		}
+
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+
+	return path, params
+}
+
+// operation builds the operation of the route, pathParams are the params of
+// the endpoint in order
+func (doc *RouteDoc) operation(pathParams []string, schemas *schemaGenerator) *OpenAPIOperation {
+	operation := &OpenAPIOperation{
+		Summary:     doc.summary,
+		Description: doc.description,
+		OperationID: doc.operationID,
+		Tags:        doc.tags,
+		Deprecated:  doc.deprecated,
+	}
+
+	for _, name := range pathParams {
+		param := OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+
+		for _, p := range doc.params {
+			if p.In == "path" && p.Name == name {
+				param.Description = p.Description
+				param.Schema = paramSchema(p, schemas)
+			}
+		}
+
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	for _, p := range doc.params {
+		if p.In == "path" {
+			continue
+		}
+
+		operation.Parameters = append(operation.Parameters, OpenAPIParameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      paramSchema(p, schemas),
+		})
+	}
+
+	if doc.body != nil {
+		operation.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  jsonContent(schemas.generate(doc.body)),
+		}
+	}
+
+	if len(doc.responses) > 0 {
+		codes := make([]int, 0, len(doc.responses))
+		for code := range doc.responses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+
+		operation.Responses = make(map[string]OpenAPIResponse, len(codes))
+		for _, code := range codes {
+			response := doc.responses[code]
+
+			res := OpenAPIResponse{Description: response.description}
+			if response.body != nil {
+				res.Content = jsonContent(schemas.generate(response.body))
+			}
+
+			operation.Responses[strconv.Itoa(code)] = res
+		}
+	}
+
+	return operation
+}
+
+func paramSchema(param ParamDoc, schemas *schemaGenerator) *Schema {
+	if param.Type == nil {
+		return &Schema{Type: "string"}
+	}
+
+	return schemas.generate(param.Type)
+}
+
+func jsonContent(schema *Schema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{MIMEApplicationJSON: {Schema: schema}}
+}
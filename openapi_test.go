@@ -0,0 +1,105 @@
+package husky
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIError struct {
+	Message string `json:"message"`
+}
+
+func openAPIService() *Husky {
+	h := New()
+	handler := func(ctx *CTX) error {
+		return ctx.NoContent(204)
+	}
+
+	h.GET("/users", handler).
+		Summary("List users").
+		Tags("users").
+		Query("page", 0, "page number").
+		Response(200, []schemaUser{}, "")
+
+	api := h.Group("/api/v1")
+	api.POST("/users", handler).
+		Summary("Create a user").
+		OperationID("createUser").
+		Tags("users").
+		Body(schemaUser{}).
+		Response(201, schemaUser{}, "Created").
+		Response(422, openAPIError{}, "Invalid user")
+
+	api.GET("/users/:id/files/*", handler).
+		Param(ParamDoc{In: "path", Name: "id", Type: 0, Description: "user id"}).
+		Param(ParamDoc{In: "header", Name: "X-Tenant", Required: true}).
+		Deprecated()
+
+	api.DELETE("/users/:id", handler).Response(204, nil, "")
+	h.GET("/internal", handler).Hidden()
+
+	return h
+}
+
+func TestOpenAPI(t *testing.T) {
+	h := openAPIService()
+	document := h.OpenAPI(OpenAPIInfo{Title: "Users", Version: "2.0.0"})
+
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	assert.Equal(t, OpenAPIInfo{Title: "Users", Version: "2.0.0"}, document.Info)
+	assert.Len(t, document.Paths, 4)
+	assert.NotContains(t, document.Paths, "/internal")
+
+	list := document.Paths["/users"]["get"]
+	assert.Equal(t, "List users", list.Summary)
+	assert.Equal(t, []string{"users"}, list.Tags)
+	assert.Equal(t, []OpenAPIParameter{
+		{Name: "page", In: "query", Description: "page number", Schema: &Schema{Type: "integer", Format: "int64"}},
+	}, list.Parameters)
+	assert.Equal(t, "OK", list.Responses["200"].Description)
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/schemaUser"}}, list.Responses["200"].Content["application/json"].Schema)
+
+	// group prefixes are part of the path
+	create := document.Paths["/api/v1/users"]["post"]
+	assert.Equal(t, "createUser", create.OperationID)
+	assert.True(t, create.RequestBody.Required)
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/schemaUser"}, create.RequestBody.Content["application/json"].Schema)
+	assert.Equal(t, "Invalid user", create.Responses["422"].Description)
+
+	files := document.Paths["/api/v1/users/{id}/files/{wildcard}"]["get"]
+	assert.True(t, files.Deprecated)
+	assert.Equal(t, []OpenAPIParameter{
+		{Name: "id", In: "path", Description: "user id", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		{Name: "wildcard", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &Schema{Type: "string"}},
+	}, files.Parameters)
+
+	remove := document.Paths["/api/v1/users/{id}"]["delete"]
+	assert.Equal(t, []OpenAPIParameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+	}, remove.Parameters)
+	assert.Equal(t, OpenAPIResponse{Description: "No Content"}, remove.Responses["204"])
+
+	assert.Len(t, document.Components.Schemas, 2)
+	assert.Contains(t, document.Components.Schemas, "schemaUser")
+	assert.Contains(t, document.Components.Schemas, "openAPIError")
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	h := openAPIService()
+	h.OpenAPIEndpoint("/openapi.json", OpenAPIInfo{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	assert.Equal(t, 200, w.Code)
+
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+	assert.Equal(t, map[string]interface{}{"title": "Husky", "version": "1.0.0"}, document["info"])
+	assert.NotContains(t, document["paths"], "/openapi.json")
+	assert.Contains(t, document["paths"], "/api/v1/users/{id}")
+}
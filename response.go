@@ -1,6 +1,11 @@
 package husky
 
-import "net/http"
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync"
+)
 
 // Response standard Husky response struct
 type Response struct {
@@ -8,6 +13,9 @@ type Response struct {
 	Status    int
 	Size      int64
 	Committed bool
+
+	mu       sync.Mutex
+	rejected error
 }
 
 // NewResponse creates new Husky Response struct
@@ -16,17 +24,113 @@ func NewResponse(w http.ResponseWriter) (r *Response) {
 }
 
 // Write writs the bytes (message) to the client
+// A 200 status is written first if no status has been sent yet
 func (response *Response) Write(b []byte) (n int, err error) {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	if response.rejected != nil {
+		return 0, response.rejected
+	}
+
+	response.writeHeader(http.StatusOK)
+
 	n, err = response.Writer.Write(b)
+	response.Size += int64(n)
 	return
 }
 
 // WriteHeader writes a header to the response writer
+// Only the first call is sent to the client, later calls are ignored
 func (response *Response) WriteHeader(code int) {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	if response.rejected != nil {
+		return
+	}
+
+	response.writeHeader(code)
+}
+
+func (response *Response) writeHeader(code int) {
+	if response.Committed {
+		return
+	}
+
+	response.Status = code
+	response.Committed = true
 	response.Writer.WriteHeader(code)
 }
 
 // Header returns the header information
+// Once the response is interrupted the handler gets a detached header map.
 func (response *Response) Header() http.Header {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	if response.rejected != nil {
+		return http.Header{}
+	}
+
 	return response.Writer.Header()
 }
+
+// Flush sends any buffered data to the client, implements http.Flusher
+func (response *Response) Flush() {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	if response.rejected != nil {
+		return
+	}
+
+	if flusher, ok := response.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, implements http.Hijacker
+func (response *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	if response.rejected != nil {
+		return nil, nil, response.rejected
+	}
+
+	hijacker, ok := response.Writer.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackUnsupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		response.Committed = true
+	}
+
+	return conn, rw, err
+}
+
+// Interrupt answers in place of a handler that may still be running, e.g.
+// after a timeout. The writer is replaced with w and every later write
+// through the response fails with err. When write is not nil the recorded
+// status and size are reset and write sends the response to w.
+func (response *Response) Interrupt(w http.ResponseWriter, err error, write func(response *Response)) {
+	response.mu.Lock()
+	defer response.mu.Unlock()
+
+	response.rejected = err
+	response.Writer = w
+
+	if write == nil {
+		return
+	}
+
+	interrupted := NewResponse(w)
+	write(interrupted)
+
+	response.Status = interrupted.Status
+	response.Size = interrupted.Size
+	response.Committed = interrupted.Committed
+}
@@ -2,12 +2,16 @@ package husky
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 )
 
 const pattern = `([aA-zZ0-9_-]+)`
 const query = `[^&?]*?=[^&?]*`
+const wildcard = `(.*)`
 
 // Router holds all defined routes
 // map[string]map[string] = [VERB][PATTERN] = [GET][/users]
@@ -17,15 +21,18 @@ type Router struct {
 
 // Route holds all information about a defined route
 type Route struct {
+	Doc        *RouteDoc           // OpenAPI documentation
 	Handler    Handler             // main handler
 	Endpoint   string              // endpoint for route
 	Middleware []MiddlewareHandler // array of middleware handlers
 	Verb       string              // http verb
 }
 
-// Add will add a new route to the Router.Routes map
-func (router *Router) Add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) {
+// Add will add a new route to the Router.Routes map and returns its
+// documentation
+func (router *Router) Add(verb string, endpoint string, handler Handler, middleware []MiddlewareHandler) *RouteDoc {
 	route := Route{
+		Doc:      &RouteDoc{},
 		Endpoint: endpoint,
 		Handler:  handler,
 		Verb:     verb,
@@ -47,40 +54,68 @@ func (router *Router) Add(verb string, endpoint string, handler Handler, middlew
 	}
 
 	router.Routes[verb][verb+endpoint] = route
+	return route.Doc
 }
 
-// FindRoute searches for requested route
+// FindRoute searches for requested route and adds the path and query params
+// to the context, the body is left untouched for the handler
 func (router *Router) FindRoute(ctx *CTX) (bool, Route) {
-	// by default route is nil, i.e. Not Found
-	var route Route
-	found := false
-
-	httpMethod := ctx.Request.Method
 	httpURI := strings.Split(ctx.Request.URL.String(), "?")
 
-	// routes to search
-	// routes := router.getRoutes(context.Request().Method)
-	for k, v := range router.Routes[ctx.Request.Method] {
-		formatted := format(k)
+	route, params, found := router.Match(ctx.Request.Method, httpURI[0])
+	if found {
+		ctx.AddParams(params)
+
+		if len(httpURI) > 1 {
+			ctx.AddParams(parseQueryParams(httpURI[1]))
+		}
+	}
+
+	return found, route
+}
+
+// Match returns the route for the method and path along with its path params
+// When several routes match, static segments win over :params and :params
+// win over * wildcards
+func (router *Router) Match(method string, path string) (Route, map[string]string, bool) {
+	var route Route
+	var key string
+	found := false
 
-		regex := regexp.MustCompile(`^` + formatted + `/?$`)
+	for k, v := range router.Routes[method] {
+		regex := regexp.MustCompile(`^` + format(k) + `/?$`)
 
-		if regex.MatchString(httpMethod + httpURI[0]) {
+		if regex.MatchString(method+path) && (!found || moreSpecific(k, key)) {
 			found = true
 			route = v
+			key = k
+		}
+	}
+
+	if !found {
+		return route, nil, false
+	}
 
-			ctx.AddParams(parseURLParams(httpMethod, httpURI[0], formatted, k))
+	return route, parseURLParams(method, path, format(key), key), true
+}
 
-			ctx.Request.ParseForm()
-			ctx.AddParams(parseFormParams(ctx.Request.Form))
+// moreSpecific reports if route a should be preferred over route b
+func moreSpecific(a string, b string) bool {
+	wa, wb := strings.Count(a, "*"), strings.Count(b, "*")
+	if wa != wb {
+		return wa < wb
+	}
 
-			if len(httpURI) > 1 {
-				ctx.AddParams(parseQueryParams(httpURI[1]))
-			}
-		}
+	pa, pb := strings.Count(a, ":"), strings.Count(b, ":")
+	if pa != pb {
+		return pa < pb
 	}
 
-	return found, route
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+
+	return a < b
 }
 
 // func (router *Router) getRoutes(method string) map[string]Route {
@@ -94,7 +129,7 @@ func (router *Router) FindRoute(ctx *CTX) (bool, Route) {
 func format(route string) string {
 	var formatted bytes.Buffer
 	re := regexp.MustCompile(`:` + pattern)
-	formatted.WriteString(re.ReplaceAllString(route, pattern))
+	formatted.WriteString(strings.Replace(re.ReplaceAllString(route, pattern), "*", wildcard, -1))
 	return formatted.String()
 }
 
@@ -106,8 +141,8 @@ func parseURLParams(method string, url string, path string, route string) map[st
 	// map of params to be returned
 	params := make(map[string]string)
 
-	// key regular expression (kre)
-	kre := regexp.MustCompile(`:` + pattern)
+	// key regular expression (kre), a * wildcard is stored as the "*" param
+	kre := regexp.MustCompile(`:` + pattern + `|\*`)
 	keys := kre.FindAllStringSubmatch(route, -1)
 
 	// value regular express (vre)
@@ -116,7 +151,11 @@ func parseURLParams(method string, url string, path string, route string) map[st
 
 	// assign keys to values
 	for i, v := range keys {
-		params[v[1]] = values[i]
+		if v[0] == "*" {
+			params["*"] = values[i]
+		} else {
+			params[v[1]] = values[i]
+		}
 	}
 
 	return params
@@ -137,14 +176,33 @@ func parseQueryParams(url string) map[string]string {
 	return params
 }
 
-func parseFormParams(form map[string][]string) map[string]string {
-	params := make(map[string]string)
+// URL builds the path of a registered route, params replace the :params of the
+// endpoint in order, e.g. URL("/users/:id", 5) returns "/users/5"
+func (router *Router) URL(endpoint string, params ...interface{}) (string, error) {
+	for _, routes := range router.Routes {
+		for _, route := range routes {
+			if route.Endpoint == endpoint {
+				return BuildURL(endpoint, params...)
+			}
+		}
+	}
 
-	for k, v := range form {
-		params[k] = v[0]
+	return "", errors.New("husky: no route registered for " + endpoint)
+}
+
+// BuildURL replaces the :params of the endpoint with params in order
+func BuildURL(endpoint string, params ...interface{}) (string, error) {
+	re := regexp.MustCompile(`:` + pattern)
+	if keys := re.FindAllString(endpoint, -1); len(keys) != len(params) {
+		return "", fmt.Errorf("husky: %s expects %d params, got %d", endpoint, len(keys), len(params))
 	}
 
-	return params
+	i := 0
+	return re.ReplaceAllStringFunc(endpoint, func(string) string {
+		value := url.PathEscape(fmt.Sprint(params[i]))
+		i++
+		return value
+	}), nil
 }
 
 // GetRoutes returns the routes of a specific http verb
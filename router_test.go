@@ -82,3 +82,29 @@ func TestRootPathReturnsEmptyMap(t *testing.T) {
 	assert.Empty(t, h.Context.GetParams())
 	assert.True(t, reflect.TypeOf(h.Context.GetParams()).String() == "map[string]string")
 }
+
+func TestFindRoutePrefersStaticSegments(t *testing.T) {
+	h := New()
+
+	h.GET("/users/*", func(c *CTX) error {
+		return c.String(200, "wildcard "+c.GetParam("*"))
+	})
+	h.GET("/users/:id", func(c *CTX) error {
+		return c.String(200, "param "+c.GetParam("id"))
+	})
+	h.GET("/users/new", func(c *CTX) error {
+		return c.String(200, "static")
+	})
+
+	for uri, expected := range map[string]string{
+		"/users/new":     "static",
+		"/users/5":       "param 5",
+		"/users/5/posts": "wildcard 5/posts",
+	} {
+		r, _ := http.NewRequest("GET", uri, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, expected, w.Body.String())
+	}
+}
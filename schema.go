@@ -0,0 +1,208 @@
+package husky
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	schemaNameRe      = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemaGenerator reflects Go types into schemas, named structs are added to
+// schemas once and referenced, so recursive types are supported
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	taken   map[string]bool
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		taken:   make(map[string]bool),
+	}
+}
+
+// generate returns the schema of the type of v, nil values accept anything
+func (g *schemaGenerator) generate(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return g.schema(reflect.TypeOf(v))
+}
+
+// schema follows the encoding rules of encoding/json
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType, implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		// []byte is encoded as a base64 string
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerType) && !implements(t.Elem(), textMarshalerType) {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.named(t)}
+	}
+
+	// interfaces and types json can not encode
+	return &Schema{}
+}
+
+// named adds the schema of a named struct on first use and returns its name
+func (g *schemaGenerator) named(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := schemaNameRe.ReplaceAllString(t.Name(), "_")
+	if g.taken[name] {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	for i, base := 2, name; g.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	g.names[t] = name
+	g.taken[name] = true
+	g.schemas[name] = g.object(t)
+
+	return name
+}
+
+// object returns the schema of the json fields of a struct
+func (g *schemaGenerator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	required := make(map[string]bool)
+
+	g.fields(t, schema, required, false)
+
+	for name, ok := range required {
+		if ok {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+// fields adds the fields of t to schema, fields of embedded structs never
+// replace the fields of the outer struct
+func (g *schemaGenerator) fields(t reflect.Type, schema *Schema, required map[string]bool, embedded bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, schema, required, true)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if _, ok := schema.Properties[name]; ok && embedded {
+			continue
+		}
+
+		fs := g.schema(field.Type)
+		if hasOption(options, "string") && (fs.Type == "integer" || fs.Type == "number" || fs.Type == "boolean") {
+			fs = &Schema{Type: "string"}
+		}
+		fs.Description = field.Tag.Get("doc")
+
+		schema.Properties[name] = fs
+		required[name] = field.Type.Kind() != reflect.Pointer && !hasOption(options, "omitempty") && !hasOption(options, "omitzero")
+	}
+}
+
+func hasOption(options string, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
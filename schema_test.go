@@ -0,0 +1,89 @@
+package husky
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+type schemaUser struct {
+	schemaAudit
+	ID       int64             `json:"id,string" doc:"unique id"`
+	Name     string            `json:"name"`
+	Email    *string           `json:"email"`
+	Admin    bool              `json:"admin,omitempty"`
+	Score    float64           `json:"score"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	IP       net.IP            `json:"ip,omitempty"`
+	Extra    json.RawMessage   `json:"extra,omitempty"`
+	Any      interface{}       `json:"any,omitempty"`
+	Manager  *schemaUser       `json:"manager,omitempty"`
+	Password string            `json:"-"`
+	internal string
+}
+
+func TestSchemaPrimitives(t *testing.T) {
+	g := newSchemaGenerator()
+
+	assert.Equal(t, &Schema{Type: "boolean"}, g.generate(true))
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, g.generate(0))
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, g.generate(int32(0)))
+	assert.Equal(t, &Schema{Type: "number", Format: "double"}, g.generate(0.5))
+	assert.Equal(t, &Schema{Type: "string"}, g.generate(""))
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, g.generate(time.Time{}))
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "integer", Format: "int64"}}, g.generate([]int{}))
+	assert.Equal(t, &Schema{}, g.generate(nil))
+	assert.Empty(t, g.schemas)
+}
+
+func TestSchemaStruct(t *testing.T) {
+	g := newSchemaGenerator()
+
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/schemaUser"}}, g.generate([]*schemaUser{}))
+
+	user := g.schemas["schemaUser"]
+	assert.Equal(t, "object", user.Type)
+	assert.Equal(t, []string{"created_at", "id", "name", "score", "tags"}, user.Required)
+
+	assert.Equal(t, &Schema{Type: "string", Description: "unique id"}, user.Properties["id"])
+	assert.Equal(t, &Schema{Type: "string"}, user.Properties["email"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, user.Properties["created_at"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, user.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "string", ContentEncoding: "base64"}, user.Properties["avatar"])
+	assert.Equal(t, &Schema{Type: "string"}, user.Properties["ip"])
+	assert.Equal(t, &Schema{}, user.Properties["extra"])
+	assert.Equal(t, &Schema{}, user.Properties["any"])
+
+	// recursive types are referenced
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/schemaUser"}, user.Properties["manager"])
+
+	assert.NotContains(t, user.Properties, "Password")
+	assert.NotContains(t, user.Properties, "internal")
+	assert.NotContains(t, user.Properties, "schemaAudit")
+	assert.Len(t, g.schemas, 1)
+}
+
+func TestSchemaAnonymousStruct(t *testing.T) {
+	g := newSchemaGenerator()
+
+	schema := g.generate(struct {
+		Token string `json:"token"`
+	}{})
+
+	assert.Equal(t, &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"token": {Type: "string"}},
+		Required:   []string{"token"},
+	}, schema)
+	assert.Empty(t, g.schemas)
+}
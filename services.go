@@ -0,0 +1,364 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotProvided is returned when resolving a type without a provider
+var ErrNotProvided = errors.New("husky: no provider")
+
+// Lifetime controls how long a provided value lives
+type Lifetime int
+
+const (
+	// LifetimeSingleton values are constructed once on first use and closed
+	// after the OnStop hooks when they implement io.Closer
+	LifetimeSingleton Lifetime = iota
+	// LifetimeRequest values are constructed once per request and closed
+	// after the response when they implement io.Closer
+	LifetimeRequest
+)
+
+var (
+	ctxType     = reflect.TypeOf((*CTX)(nil))
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// container holds the providers of a Husky service
+type container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	built     []*provider // singletons in construction order
+}
+
+// provider constructs values of a type from its dependencies
+type provider struct {
+	typ         reflect.Type
+	constructor reflect.Value
+	deps        []reflect.Type
+	lifetime    Lifetime
+	returnsErr  bool
+
+	mu    sync.Mutex
+	value reflect.Value
+	built bool
+}
+
+// Provide registers the constructor of T. The constructor is a function
+// returning T, or T and an error, whose parameters are other provided types;
+// request scoped constructors may also take *CTX or context.Context.
+// Providing a type again replaces its provider, e.g. with a fake in tests.
+// Panics if the constructor has an invalid signature.
+func Provide[T any](h *Husky, lifetime Lifetime, constructor interface{}) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	fn := reflect.ValueOf(constructor)
+
+	if fn.Kind() != reflect.Func {
+		panic("husky: provider of " + typ.String() + " must be a function")
+	}
+
+	ft := fn.Type()
+	if ft.IsVariadic() || ft.NumOut() < 1 || ft.NumOut() > 2 || !ft.Out(0).AssignableTo(typ) || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		panic("husky: provider of " + typ.String() + " must return " + typ.String() + " or (" + typ.String() + ", error)")
+	}
+
+	p := &provider{
+		typ:         typ,
+		constructor: fn,
+		lifetime:    lifetime,
+		returnsErr:  ft.NumOut() == 2,
+	}
+
+	for i := 0; i < ft.NumIn(); i++ {
+		p.deps = append(p.deps, ft.In(i))
+	}
+
+	h.services().add(p)
+}
+
+// ProvideValue registers value as the singleton T
+func ProvideValue[T any](h *Husky, value T) {
+	Provide[T](h, LifetimeSingleton, func() T {
+		return value
+	})
+}
+
+// Resolve returns the T of the request, constructing it and its dependencies
+// on first use
+func Resolve[T any](ctx *CTX) (T, error) {
+	var zero T
+
+	if ctx.husky == nil {
+		return zero, ErrNotProvided
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	value, err := ctx.husky.services().resolve(typ, ctx, nil)
+	if err != nil {
+		return zero, err
+	}
+
+	return value.Interface().(T), nil
+}
+
+// MustResolve returns the T of the request and panics if it can not be resolved
+func MustResolve[T any](ctx *CTX) T {
+	value, err := Resolve[T](ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// ValidateServices checks every provider for missing dependencies, cycles and
+// singletons depending on request scoped values, Start calls it before the
+// OnStart hooks
+func (husky *Husky) ValidateServices() error {
+	return husky.services().validate()
+}
+
+// services returns the container of the service
+func (husky *Husky) services() *container {
+	husky.mu.Lock()
+	defer husky.mu.Unlock()
+
+	if husky.container == nil {
+		husky.container = &container{providers: make(map[reflect.Type]*provider)}
+	}
+
+	return husky.container
+}
+
+func (c *container) add(p *provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.providers[p.typ] = p
+}
+
+func (c *container) get(typ reflect.Type) (*provider, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.providers[typ]
+	return p, ok
+}
+
+// resolve returns the value of typ, path holds the types being constructed
+// to report cycles of providers added after validation
+func (c *container) resolve(typ reflect.Type, ctx *CTX, path []reflect.Type) (reflect.Value, error) {
+	switch typ {
+	case ctxType:
+		return reflect.ValueOf(ctx), nil
+	case contextType:
+		return reflect.ValueOf(ctx.Context()), nil
+	}
+
+	p, ok := c.get(typ)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w for %s", ErrNotProvided, typ)
+	}
+
+	for _, t := range path {
+		if t == typ {
+			return reflect.Value{}, fmt.Errorf("husky: dependency cycle %s", formatPath(append(path, typ)))
+		}
+	}
+	path = append(path, typ)
+
+	if p.lifetime == LifetimeSingleton {
+		return c.singleton(p, ctx, path)
+	}
+
+	if value, ok := ctx.services[typ]; ok {
+		return value, nil
+	}
+
+	value, err := c.construct(p, ctx, path)
+	if err != nil {
+		return value, err
+	}
+
+	if ctx.services == nil {
+		ctx.services = make(map[reflect.Type]reflect.Value)
+	}
+	ctx.services[typ] = value
+
+	if closer, ok := value.Interface().(io.Closer); ok {
+		ctx.OnCleanup(func() {
+			closer.Close()
+		})
+	}
+
+	return value, nil
+}
+
+// singleton constructs the value of p once, failed constructions are retried
+func (c *container) singleton(p *provider, ctx *CTX, path []reflect.Type) (reflect.Value, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.built {
+		return p.value, nil
+	}
+
+	value, err := c.construct(p, ctx, path)
+	if err != nil {
+		return value, err
+	}
+
+	p.value = value
+	p.built = true
+
+	c.mu.Lock()
+	c.built = append(c.built, p)
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// close closes the singletons implementing io.Closer in reverse construction
+// order, so values are closed before their dependencies
+func (c *container) close() error {
+	c.mu.Lock()
+	built := c.built
+	c.built = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(built) - 1; i >= 0; i-- {
+		p := built[i]
+
+		p.mu.Lock()
+		value := p.value
+		p.value, p.built = reflect.Value{}, false
+		p.mu.Unlock()
+
+		if closer, ok := value.Interface().(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// construct calls the constructor with its resolved dependencies
+func (c *container) construct(p *provider, ctx *CTX, path []reflect.Type) (reflect.Value, error) {
+	args := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		if p.lifetime == LifetimeSingleton && (dep == ctxType || dep == contextType) {
+			return reflect.Value{}, fmt.Errorf("husky: singleton %s can not depend on %s", p.typ, dep)
+		}
+
+		if d, ok := c.get(dep); ok && p.lifetime == LifetimeSingleton && d.lifetime == LifetimeRequest {
+			return reflect.Value{}, fmt.Errorf("husky: singleton %s can not depend on request scoped %s", p.typ, dep)
+		}
+
+		value, err := c.resolve(dep, ctx, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = value
+	}
+
+	out := p.constructor.Call(args)
+	if p.returnsErr && !out[1].IsNil() {
+		return reflect.Value{}, out[1].Interface().(error)
+	}
+
+	// convert implementations to the provided interface type
+	value := reflect.New(p.typ).Elem()
+	value.Set(out[0])
+	return value, nil
+}
+
+// validate walks the dependencies of every provider
+func (c *container) validate() error {
+	c.mu.Lock()
+	providers := make(map[reflect.Type]*provider, len(c.providers))
+	for typ, p := range c.providers {
+		providers[typ] = p
+	}
+	c.mu.Unlock()
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[reflect.Type]int)
+
+	var visit func(typ reflect.Type, path []reflect.Type) error
+	visit = func(typ reflect.Type, path []reflect.Type) error {
+		path = append(path, typ)
+
+		switch state[typ] {
+		case visiting:
+			return fmt.Errorf("husky: dependency cycle %s", formatPath(path))
+		case done:
+			return nil
+		}
+		state[typ] = visiting
+
+		p := providers[typ]
+		for _, dep := range p.deps {
+			if dep == ctxType || dep == contextType {
+				if p.lifetime == LifetimeSingleton {
+					return fmt.Errorf("husky: singleton %s can not depend on %s", typ, dep)
+				}
+				continue
+			}
+
+			d, ok := providers[dep]
+			if !ok {
+				return fmt.Errorf("%w for %s required by %s", ErrNotProvided, dep, typ)
+			}
+
+			if p.lifetime == LifetimeSingleton && d.lifetime == LifetimeRequest {
+				return fmt.Errorf("husky: singleton %s can not depend on request scoped %s", typ, dep)
+			}
+
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+
+		state[typ] = done
+		return nil
+	}
+
+	types := make([]reflect.Type, 0, len(providers))
+	for typ := range providers {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+
+	for _, typ := range types {
+		if err := visit(typ, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatPath(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, typ := range path {
+		names[i] = typ.String()
+	}
+
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,168 @@
+package husky
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type store interface {
+	Name() string
+}
+
+type database struct {
+	name string
+}
+
+func (d *database) Name() string {
+	return d.name
+}
+
+type repository struct {
+	store  store
+	closed bool
+	path   string
+}
+
+func (r *repository) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestProvideAndResolve(t *testing.T) {
+	h := New()
+
+	builds := 0
+	Provide[store](h, LifetimeSingleton, func() *database {
+		builds++
+		return &database{name: "primary"}
+	})
+	Provide[*repository](h, LifetimeRequest, func(s store, ctx *CTX) *repository {
+		return &repository{store: s, path: ctx.Request.URL.Path}
+	})
+	assert.NoError(t, h.ValidateServices())
+
+	var repositories []*repository
+	h.GET("/users", func(ctx *CTX) error {
+		repo := MustResolve[*repository](ctx)
+		again := MustResolve[*repository](ctx)
+		assert.True(t, repo == again)
+
+		repositories = append(repositories, repo)
+		return ctx.String(200, repo.store.Name()+" "+repo.path)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+		assert.Equal(t, "primary /users", w.Body.String())
+	}
+
+	assert.Equal(t, 1, builds)
+	assert.True(t, repositories[0] != repositories[1])
+
+	// request scoped values are closed after the response
+	assert.True(t, repositories[0].closed)
+	assert.True(t, repositories[1].closed)
+}
+
+func TestProvideReplacesProvider(t *testing.T) {
+	h := New()
+
+	ProvideValue[store](h, &database{name: "primary"})
+	ProvideValue[store](h, &database{name: "fake"})
+
+	s, err := Resolve[store](h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", s.Name())
+}
+
+func TestResolveErrors(t *testing.T) {
+	h := New()
+	c := h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	_, err := Resolve[store](c)
+	assert.True(t, errors.Is(err, ErrNotProvided))
+
+	Provide[store](h, LifetimeSingleton, func() (*database, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err = Resolve[store](c)
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestValidateServices(t *testing.T) {
+	h := New()
+	Provide[store](h, LifetimeSingleton, func(r *repository) *database {
+		return &database{}
+	})
+	Provide[*repository](h, LifetimeSingleton, func(s store) *repository {
+		return &repository{store: s}
+	})
+	assert.EqualError(t, h.ValidateServices(), "husky: dependency cycle *husky.repository -> husky.store -> *husky.repository")
+
+	// Start validates before the OnStart hooks
+	assert.Error(t, h.Start())
+
+	h = New()
+	Provide[*repository](h, LifetimeSingleton, func(s store) *repository {
+		return &repository{store: s}
+	})
+	assert.True(t, errors.Is(h.ValidateServices(), ErrNotProvided))
+
+	h = New()
+	Provide[store](h, LifetimeSingleton, func(ctx context.Context) *database {
+		return &database{}
+	})
+	assert.EqualError(t, h.ValidateServices(), "husky: singleton husky.store can not depend on context.Context")
+
+	h = New()
+	Provide[store](h, LifetimeSingleton, func(r *repository) *database {
+		return &database{}
+	})
+	Provide[*repository](h, LifetimeRequest, func() *repository {
+		return &repository{}
+	})
+	assert.EqualError(t, h.ValidateServices(), "husky: singleton husky.store can not depend on request scoped *husky.repository")
+}
+
+func TestProvidePanicsOnInvalidConstructor(t *testing.T) {
+	h := New()
+
+	assert.Panics(t, func() {
+		Provide[store](h, LifetimeSingleton, &database{})
+	})
+	assert.Panics(t, func() {
+		Provide[store](h, LifetimeSingleton, func() string { return "" })
+	})
+}
+
+func TestShutdownClosesSingletons(t *testing.T) {
+	h := New()
+
+	var order []string
+	Provide[*repository](h, LifetimeSingleton, func(d *database) *repository {
+		return &repository{path: "repository"}
+	})
+	ProvideValue[*database](h, &database{name: "main"})
+
+	repo := MustResolve[*repository](h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+	h.OnStop(func(ctx context.Context) error {
+		order = append(order, "stop")
+		assert.False(t, repo.closed)
+		return nil
+	})
+
+	assert.NoError(t, h.start(context.Background()))
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"stop"}, order)
+	assert.True(t, repo.closed)
+
+	// a restarted service constructs a new singleton
+	assert.False(t, MustResolve[*repository](h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))).closed)
+}
@@ -0,0 +1,159 @@
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore keeps each session in a gob encoded file, custom types stored in
+// sessions must be registered with gob.Register
+type FileStore struct {
+	dir string
+
+	mu    sync.Mutex
+	swept time.Time
+}
+
+type fileEntry struct {
+	Data    *Data
+	Expires time.Time
+}
+
+// NewFileStore creates a FileStore writing to dir, the directory is created
+// if it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Load decodes the session file for id
+func (store *FileStore) Load(id string) (*Data, error) {
+	path, err := store.path(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entry fileEntry
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&entry); err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(entry.Expires) {
+		os.Remove(path)
+		return nil, ErrNotFound
+	}
+
+	return entry.Data, nil
+}
+
+// Save atomically writes the session file for id, expired files are swept
+// in the background at most once a minute
+func (store *FileStore) Save(id string, data *Data, ttl time.Duration) error {
+	path, err := store.path(id)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(fileEntry{Data: data, Expires: time.Now().Add(ttl)}); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(store.dir, ".session_")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(b.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// the modification time holds the expiry so sweeps never decode files
+	expires := time.Now().Add(ttl)
+	if err := os.Chtimes(tmp.Name(), expires, expires); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	now := time.Now()
+	store.mu.Lock()
+	if now.Sub(store.swept) > time.Minute {
+		store.swept = now
+		go store.sweep(now)
+	}
+	store.mu.Unlock()
+
+	return nil
+}
+
+// sweep removes the session files which expired before now
+func (store *FileStore) sweep(now time.Time) {
+	files, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), "session_") && now.After(file.ModTime()) {
+			os.Remove(filepath.Join(store.dir, file.Name()))
+		}
+	}
+}
+
+// Delete removes the session file for id
+func (store *FileStore) Delete(id string) error {
+	path, err := store.path(id)
+	if err != nil {
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the file for id, rejecting IDs that could escape the directory
+func (store *FileStore) path(id string) (string, error) {
+	if id == "" {
+		return "", errors.New("session: empty id")
+	}
+
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", errors.New("session: invalid id")
+		}
+	}
+
+	return filepath.Join(store.dir, "session_"+id), nil
+}
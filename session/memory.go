@@ -0,0 +1,69 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps sessions in memory, sessions are lost on restart
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+	swept    time.Time
+}
+
+type memoryEntry struct {
+	data    *Data
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memoryEntry)}
+}
+
+// Load returns a copy of the data saved for id
+func (store *MemoryStore) Load(id string) (*Data, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(store.sessions, id)
+		return nil, ErrNotFound
+	}
+
+	return entry.data.copy(), nil
+}
+
+// Save stores a copy of the data, expired sessions are swept at most once a minute
+func (store *MemoryStore) Save(id string, data *Data, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.swept) > time.Minute {
+		for k, entry := range store.sessions {
+			if now.After(entry.expires) {
+				delete(store.sessions, k)
+			}
+		}
+		store.swept = now
+	}
+
+	store.sessions[id] = memoryEntry{data: data.copy(), expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes the session
+func (store *MemoryStore) Delete(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.sessions, id)
+	return nil
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vetebase/husky"
+)
+
+// Config configuration for the session Middleware
+type Config struct {
+	Store           Store
+	CookieName      string
+	CookiePath      string
+	CookieDomain    string
+	CookieSecure    bool
+	CookieSameSite  http.SameSite
+	IdleTimeout     time.Duration // session expires after this long without requests
+	AbsoluteTimeout time.Duration // session expires this long after creation regardless of use
+}
+
+// DefaultConfig handles the default session configuration for Husky
+var DefaultConfig = Config{
+	CookieName:      "husky_session",
+	CookiePath:      "/",
+	CookieSameSite:  http.SameSiteLaxMode,
+	IdleTimeout:     30 * time.Minute,
+	AbsoluteTimeout: 24 * time.Hour,
+}
+
+// CurrentKey holds the session of the request
+var CurrentKey = husky.NewKey[*Session]("session")
+
+// Current returns the session loaded by the Middleware, nil if the
+// middleware is not installed for the route
+func Current(ctx *husky.CTX) *Session {
+	s, _ := CurrentKey.Get(ctx)
+	return s
+}
+
+// Middleware loads the session from store for every request
+func Middleware(store Store) func(next husky.Handler) husky.Handler {
+	config := DefaultConfig
+	config.Store = store
+
+	return MiddlewareConfigured(config)
+}
+
+// MiddlewareConfigured returns a configured session Middleware, an in memory
+// store is used if none is set
+func MiddlewareConfigured(config Config) func(next husky.Handler) husky.Handler {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+
+	if config.CookieName == "" {
+		config.CookieName = DefaultConfig.CookieName
+	}
+
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultConfig.CookiePath
+	}
+
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultConfig.CookieSameSite
+	}
+
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultConfig.IdleTimeout
+	}
+
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = DefaultConfig.AbsoluteTimeout
+	}
+
+	return func(next husky.Handler) husky.Handler {
+		return func(ctx *husky.CTX) error {
+			s := load(ctx, &config)
+			CurrentKey.Set(ctx, s)
+
+			err := next(ctx)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if s.destroyed || (s.isNew && !s.modified) {
+				return err
+			}
+
+			s.data.Accessed = time.Now()
+			if saveErr := config.Store.Save(s.id, s.data, time.Until(s.expires())); saveErr != nil {
+				log.Printf("Session error: %s", saveErr)
+			}
+
+			return err
+		}
+	}
+}
+
+// load returns the session of the request, creating a new one if the cookie
+// is missing or the stored session has expired
+func load(ctx *husky.CTX, config *Config) *Session {
+	s := &Session{ctx: ctx, config: config}
+
+	if cookie, err := ctx.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+		data, err := config.Store.Load(cookie.Value)
+		if err != nil && err != ErrNotFound {
+			log.Printf("Session error: %s", err)
+		}
+
+		if data != nil {
+			now := time.Now()
+			idle := now.Sub(data.Accessed) > config.IdleTimeout
+			absolute := now.Sub(data.Created) > config.AbsoluteTimeout
+
+			if !idle && !absolute {
+				if data.Values == nil {
+					data.Values = make(map[string]interface{})
+				}
+				if data.Flashes == nil {
+					data.Flashes = make(map[string][]interface{})
+				}
+
+				s.id = cookie.Value
+				s.data = data
+
+				// slide the cookie expiry forward
+				s.setCookie()
+				return s
+			}
+
+			config.Store.Delete(cookie.Value)
+		}
+	}
+
+	s.id = newID()
+	s.data = newData()
+	s.isNew = true
+
+	return s
+}
@@ -0,0 +1,213 @@
+// Package session provides server side sessions for Husky services
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vetebase/husky"
+)
+
+// Session holds the values of a single client between requests
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	data      *Data
+	ctx       *husky.CTX
+	config    *Config
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// ID returns the session ID
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.id
+}
+
+// IsNew reports if the session was created during this request
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.isNew
+}
+
+// Get returns the value stored for key
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.data.Values[key]
+	return value, ok
+}
+
+// Set stores value for key
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Values[key] = value
+	s.touch()
+}
+
+// Delete removes the value stored for key
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Values, key)
+	s.touch()
+}
+
+// Flash adds a value for key that is removed once read with Flashes
+func (s *Session) Flash(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Flashes[key] = append(s.data.Flashes[key], value)
+	s.touch()
+}
+
+// Flashes returns and removes the flash values for key
+func (s *Session) Flashes(key string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flashes, ok := s.data.Flashes[key]
+	if ok {
+		delete(s.data.Flashes, key)
+		s.touch()
+	}
+
+	return flashes
+}
+
+// Regenerate moves the session to a new ID and removes the old one from the
+// store, call it whenever the privilege level changes such as on login
+func (s *Session) Regenerate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.config.Store.Delete(s.id); err != nil {
+		return err
+	}
+
+	s.id = newID()
+	s.modified = true
+	s.setCookie()
+
+	return nil
+}
+
+// Destroy removes the session from the store and expires the cookie
+func (s *Session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.destroyed = true
+	s.data = newData()
+
+	s.clearCookie()
+	s.ctx.SetCookie(&http.Cookie{
+		Name:     s.config.CookieName,
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+		Secure:   s.config.CookieSecure,
+		HttpOnly: true,
+		SameSite: s.config.CookieSameSite,
+	})
+
+	return s.config.Store.Delete(s.id)
+}
+
+// Value returns the value stored for key as type T
+func Value[T any](s *Session, key string) (T, bool) {
+	value, ok := s.Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	typed, ok := value.(T)
+	return typed, ok
+}
+
+// touch marks the session as modified, new sessions only get a cookie once
+// they hold data; callers must hold the lock
+func (s *Session) touch() {
+	if !s.modified && s.isNew && !s.destroyed {
+		s.setCookie()
+	}
+
+	s.modified = true
+}
+
+// expires returns when the session ends, whichever timeout comes first
+func (s *Session) expires() time.Time {
+	idle := time.Now().Add(s.config.IdleTimeout)
+	absolute := s.data.Created.Add(s.config.AbsoluteTimeout)
+
+	if absolute.Before(idle) {
+		return absolute
+	}
+
+	return idle
+}
+
+// setCookie sends the session ID to the client; callers must hold the lock
+func (s *Session) setCookie() {
+	expires := s.expires()
+
+	s.clearCookie()
+	s.ctx.SetCookie(&http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    s.id,
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		Expires:  expires,
+		MaxAge:   int(time.Until(expires).Seconds()),
+		Secure:   s.config.CookieSecure,
+		HttpOnly: true,
+		SameSite: s.config.CookieSameSite,
+	})
+}
+
+// clearCookie removes a session cookie already added to the response so the
+// client only receives the latest one; callers must hold the lock
+func (s *Session) clearCookie() {
+	header := s.ctx.Response.Header()
+	prefix := s.config.CookieName + "="
+
+	cookies := header["Set-Cookie"][:0]
+	for _, cookie := range header["Set-Cookie"] {
+		if !strings.HasPrefix(cookie, prefix) {
+			cookies = append(cookies, cookie)
+		}
+	}
+
+	if len(cookies) == 0 {
+		header.Del("Set-Cookie")
+	} else {
+		header["Set-Cookie"] = cookies
+	}
+}
+
+// newID returns 256 random bits encoded as URL safe base64
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("session: could not generate id: " + err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
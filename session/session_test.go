@@ -0,0 +1,211 @@
+package session
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetebase/husky"
+)
+
+// serve runs handler through the middleware, sending cookie if it is set
+func serve(mw func(husky.Handler) husky.Handler, cookie *http.Cookie, handler husky.Handler) *httptest.ResponseRecorder {
+	h := husky.New()
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+
+	mw(handler)(h.NewContext(w, r))
+	return w
+}
+
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == DefaultConfig.CookieName {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+func TestNewSessionIsOnlySavedWhenModified(t *testing.T) {
+	store := NewMemoryStore()
+	mw := Middleware(store)
+
+	w := serve(mw, nil, func(ctx *husky.CTX) error {
+		assert.True(t, Current(ctx).IsNew())
+		return nil
+	})
+	assert.Nil(t, sessionCookie(w))
+
+	w = serve(mw, nil, func(ctx *husky.CTX) error {
+		Current(ctx).Set("user", 1)
+		return nil
+	})
+
+	cookie := sessionCookie(w)
+	if assert.NotNil(t, cookie) {
+		assert.True(t, cookie.HttpOnly)
+
+		data, err := store.Load(cookie.Value)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, data.Values["user"])
+	}
+}
+
+func TestSessionValuesPersist(t *testing.T) {
+	mw := Middleware(NewMemoryStore())
+
+	w := serve(mw, nil, func(ctx *husky.CTX) error {
+		s := Current(ctx)
+		s.Set("user", "john")
+		s.Set("tmp", true)
+		s.Flash("notice", "saved")
+		return nil
+	})
+	cookie := sessionCookie(w)
+
+	serve(mw, cookie, func(ctx *husky.CTX) error {
+		s := Current(ctx)
+
+		user, ok := Value[string](s, "user")
+		assert.True(t, ok)
+		assert.Equal(t, "john", user)
+
+		_, ok = Value[int](s, "user")
+		assert.False(t, ok)
+
+		s.Delete("tmp")
+		assert.Equal(t, []interface{}{"saved"}, s.Flashes("notice"))
+		return nil
+	})
+
+	serve(mw, cookie, func(ctx *husky.CTX) error {
+		s := Current(ctx)
+
+		_, ok := s.Get("tmp")
+		assert.False(t, ok)
+		assert.Empty(t, s.Flashes("notice"))
+		return nil
+	})
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	store := NewMemoryStore()
+	mw := Middleware(store)
+
+	w := serve(mw, nil, func(ctx *husky.CTX) error {
+		Current(ctx).Set("user", "john")
+		return nil
+	})
+	old := sessionCookie(w)
+
+	w = serve(mw, old, func(ctx *husky.CTX) error {
+		return Current(ctx).Regenerate()
+	})
+	regenerated := sessionCookie(w)
+
+	assert.NotEqual(t, old.Value, regenerated.Value)
+
+	_, err := store.Load(old.Value)
+	assert.Equal(t, ErrNotFound, err)
+
+	data, err := store.Load(regenerated.Value)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "john", data.Values["user"])
+	}
+}
+
+func TestSessionDestroy(t *testing.T) {
+	store := NewMemoryStore()
+	mw := Middleware(store)
+
+	w := serve(mw, nil, func(ctx *husky.CTX) error {
+		Current(ctx).Set("user", "john")
+		return nil
+	})
+	cookie := sessionCookie(w)
+
+	w = serve(mw, cookie, func(ctx *husky.CTX) error {
+		return Current(ctx).Destroy()
+	})
+
+	assert.Equal(t, -1, sessionCookie(w).MaxAge)
+
+	_, err := store.Load(cookie.Value)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	config := DefaultConfig
+	config.Store = NewMemoryStore()
+	config.IdleTimeout = 10 * time.Millisecond
+	mw := MiddlewareConfigured(config)
+
+	w := serve(mw, nil, func(ctx *husky.CTX) error {
+		Current(ctx).Set("user", "john")
+		return nil
+	})
+	cookie := sessionCookie(w)
+
+	time.Sleep(20 * time.Millisecond)
+
+	serve(mw, cookie, func(ctx *husky.CTX) error {
+		assert.True(t, Current(ctx).IsNew())
+		return nil
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "husky-session")
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	assert.NoError(t, err)
+
+	data := newData()
+	data.Values["user"] = "john"
+
+	assert.NoError(t, store.Save("abc", data, time.Minute))
+
+	loaded, err := store.Load("abc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "john", loaded.Values["user"])
+	}
+
+	assert.NoError(t, store.Delete("abc"))
+
+	_, err = store.Load("abc")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = store.Load("../abc")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestFileStoreSweepsExpiredFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "husky-session")
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Save("short", newData(), time.Second))
+	assert.NoError(t, store.Save("long", newData(), time.Hour))
+
+	store.sweep(time.Now().Add(time.Minute))
+
+	_, err = os.Stat(filepath.Join(dir, "session_short"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = store.Load("long")
+	assert.NoError(t, err)
+}
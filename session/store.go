@@ -0,0 +1,62 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when no session exists for an ID
+var ErrNotFound = errors.New("session: not found")
+
+// Store persists session data between requests, implementations must be
+// safe for concurrent use
+type Store interface {
+	// Load returns the data saved for id or ErrNotFound
+	Load(id string) (*Data, error)
+
+	// Save stores the data for id, it may be discarded after ttl
+	Save(id string, data *Data, ttl time.Duration) error
+
+	// Delete removes the data saved for id
+	Delete(id string) error
+}
+
+// Data is the persisted state of a session
+type Data struct {
+	Values   map[string]interface{}
+	Flashes  map[string][]interface{}
+	Created  time.Time
+	Accessed time.Time
+}
+
+// newData creates empty session data
+func newData() *Data {
+	now := time.Now()
+
+	return &Data{
+		Values:   make(map[string]interface{}),
+		Flashes:  make(map[string][]interface{}),
+		Created:  now,
+		Accessed: now,
+	}
+}
+
+// copy returns a copy of the data so stores never share maps with requests
+func (data *Data) copy() *Data {
+	c := &Data{
+		Values:   make(map[string]interface{}, len(data.Values)),
+		Flashes:  make(map[string][]interface{}, len(data.Flashes)),
+		Created:  data.Created,
+		Accessed: data.Accessed,
+	}
+
+	for k, v := range data.Values {
+		c.Values[k] = v
+	}
+
+	for k, v := range data.Flashes {
+		c.Flashes[k] = append([]interface{}(nil), v...)
+	}
+
+	return c
+}
@@ -0,0 +1,187 @@
+package husky
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamClosed is returned when sending to a closed event stream
+var ErrStreamClosed = errors.New("husky: event stream closed")
+
+// ErrStreamingUnsupported is returned when the response can not be flushed
+var ErrStreamingUnsupported = errors.New("husky: streaming unsupported")
+
+// DefaultHeartbeat is the interval of the comments keeping idle streams open
+var DefaultHeartbeat = 15 * time.Second
+
+// Event is a single Server-Sent Event
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventStream writes Server-Sent Events to the client
+type EventStream struct {
+	ctx       *CTX
+	mu        sync.Mutex
+	flusher   http.Flusher
+	heartbeat *time.Ticker
+	done      chan struct{}
+	closed    bool
+}
+
+// SSE starts a Server-Sent Events stream, heartbeats are sent every
+// DefaultHeartbeat and the stream closes when the client disconnects or the
+// request has been served
+func (ctx *CTX) SSE() (*EventStream, error) {
+	var flusher http.Flusher = ctx.Response
+	if _, ok := ctx.Response.Writer.(http.Flusher); !ok {
+		return nil, ErrStreamingUnsupported
+	}
+
+	header := ctx.Response.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+
+	ctx.Response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &EventStream{
+		ctx:     ctx,
+		flusher: flusher,
+		done:    make(chan struct{}),
+	}
+
+	if DefaultHeartbeat > 0 {
+		stream.heartbeat = time.NewTicker(DefaultHeartbeat)
+	}
+	go stream.keepAlive()
+	ctx.OnCleanup(stream.Close)
+
+	return stream, nil
+}
+
+// LastEventID returns the ID sent by a reconnecting client to resume from
+func (stream *EventStream) LastEventID() string {
+	if id := stream.ctx.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+
+	return stream.ctx.Request.URL.Query().Get("lastEventId")
+}
+
+// Done is closed when the client disconnects or the stream is closed
+func (stream *EventStream) Done() <-chan struct{} {
+	return stream.done
+}
+
+// Send writes and flushes the event
+func (stream *EventStream) Send(event Event) error {
+	var b strings.Builder
+
+	if event.ID != "" {
+		writeField(&b, "id", singleLine(event.ID))
+	}
+
+	if event.Event != "" {
+		writeField(&b, "event", singleLine(event.Event))
+	}
+
+	if event.Retry > 0 {
+		writeField(&b, "retry", strconv.FormatInt(int64(event.Retry/time.Millisecond), 10))
+	}
+
+	for _, line := range strings.Split(lineBreaks.Replace(event.Data), "\n") {
+		writeField(&b, "data", line)
+	}
+
+	b.WriteString("\n")
+	return stream.write(b.String())
+}
+
+// Close stops the heartbeat, later sends return ErrStreamClosed
+func (stream *EventStream) Close() {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if stream.closed {
+		return
+	}
+
+	stream.closed = true
+	if stream.heartbeat != nil {
+		stream.heartbeat.Stop()
+	}
+	close(stream.done)
+}
+
+// keepAlive sends comment lines until the client disconnects
+func (stream *EventStream) keepAlive() {
+	clientGone := stream.ctx.Context().Done()
+
+	var tick <-chan time.Time
+	if stream.heartbeat != nil {
+		tick = stream.heartbeat.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			if err := stream.write(": heartbeat\n\n"); err != nil {
+				stream.Close()
+				return
+			}
+		case <-clientGone:
+			stream.Close()
+			return
+		case <-stream.done:
+			return
+		}
+	}
+}
+
+// write sends the raw text and flushes it to the client
+func (stream *EventStream) write(s string) error {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if stream.closed {
+		return ErrStreamClosed
+	}
+
+	select {
+	case <-stream.ctx.Context().Done():
+		return ErrStreamClosed
+	default:
+	}
+
+	if _, err := stream.ctx.Response.Write([]byte(s)); err != nil {
+		return err
+	}
+
+	stream.flusher.Flush()
+	return nil
+}
+
+func writeField(b *strings.Builder, name string, value string) {
+	b.WriteString(name)
+	b.WriteString(": ")
+	b.WriteString(value)
+	b.WriteString("\n")
+}
+
+// lineBreaks normalizes the CRLF, CR and LF line endings of event data
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// singleLine removes line breaks which would end a field early
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,103 @@
+package husky
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSESendsEvents(t *testing.T) {
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/events", nil))
+
+	stream, err := c.SSE()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	stream.Send(Event{ID: "1", Event: "update", Data: "line 1\nline 2", Retry: 3 * time.Second})
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "id: 1\nevent: update\nretry: 3000\ndata: line 1\ndata: line 2\n\n", w.Body.String())
+
+	w.Body.Reset()
+	stream.Send(Event{Data: "a\r\nb\rc\nd"})
+	assert.Equal(t, "data: a\ndata: b\ndata: c\ndata: d\n\n", w.Body.String())
+}
+
+func TestSSELastEventID(t *testing.T) {
+	h := New()
+
+	r := httptest.NewRequest("GET", "/events", nil)
+	r.Header.Set("Last-Event-ID", "42")
+	c := h.NewContext(httptest.NewRecorder(), r)
+
+	stream, _ := c.SSE()
+	defer stream.Close()
+
+	assert.Equal(t, "42", stream.LastEventID())
+}
+
+func TestSSEStopsOnDisconnect(t *testing.T) {
+	h := New()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(cancelled)
+	c := h.NewContext(httptest.NewRecorder(), r)
+
+	stream, _ := c.SSE()
+	cancel()
+
+	select {
+	case <-stream.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed")
+	}
+
+	assert.Equal(t, ErrStreamClosed, stream.Send(Event{Data: "late"}))
+}
+
+func TestSSEClosesAfterRequest(t *testing.T) {
+	var stream *EventStream
+
+	h := New()
+	h.GET("/events", func(ctx *CTX) error {
+		stream, _ = ctx.SSE()
+		return nil
+	})
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+
+	select {
+	case <-stream.Done():
+	default:
+		t.Fatal("stream was not closed")
+	}
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	heartbeat := DefaultHeartbeat
+	DefaultHeartbeat = 5 * time.Millisecond
+	defer func() { DefaultHeartbeat = heartbeat }()
+
+	h := New()
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/events", nil))
+
+	stream, _ := c.SSE()
+	time.Sleep(20 * time.Millisecond)
+	stream.Close()
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	assert.Contains(t, w.Body.String(), ": heartbeat\n\n")
+}
@@ -0,0 +1,278 @@
+package husky
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StaticConfig configuration for serving static files
+type StaticConfig struct {
+	FS          fs.FS    // files to serve, e.g. os.DirFS("public") or an embed.FS
+	Index       string   // file served for directories, defaults to "index.html"
+	Browse      bool     // lists directories without an index file
+	SPA         bool     // serves the root index for unknown paths
+	APIPrefixes []string // paths that never fall back to the SPA index, e.g. "/api"
+}
+
+// DefaultStaticConfig handles the default static configuration for Husky
+var DefaultStaticConfig = StaticConfig{
+	Index: "index.html",
+}
+
+var listing = template.Must(template.New("listing").Parse(
+	`<!doctype html><meta charset="utf-8"><title>{{ .Path }}</title><h1>{{ .Path }}</h1><ul>` +
+		`{{ range .Entries }}<li><a href="{{ .URL }}">{{ .Name }}</a></li>{{ end }}</ul>`,
+))
+
+// Static serves the files of the root directory under prefix
+func (husky *Husky) Static(prefix string, root string, middleware ...MiddlewareHandler) {
+	config := DefaultStaticConfig
+	config.FS = os.DirFS(root)
+
+	husky.StaticConfigured(prefix, config, middleware...)
+}
+
+// StaticFS serves the files of fsys under prefix, use fs.Sub to serve a
+// sub directory of an embed.FS
+func (husky *Husky) StaticFS(prefix string, fsys fs.FS, middleware ...MiddlewareHandler) {
+	config := DefaultStaticConfig
+	config.FS = fsys
+
+	husky.StaticConfigured(prefix, config, middleware...)
+}
+
+// StaticConfigured serves static files under prefix with the given configuration
+func (husky *Husky) StaticConfigured(prefix string, config StaticConfig, middleware ...MiddlewareHandler) {
+	if config.Index == "" {
+		config.Index = DefaultStaticConfig.Index
+	}
+
+	handler := func(ctx *CTX) error {
+		name, err := url.PathUnescape(ctx.GetParam("*"))
+		if err != nil {
+			return NotFoundHandler(ctx)
+		}
+
+		return serveStatic(ctx, config, name)
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	for _, verb := range []string{"GET", "HEAD"} {
+		husky.add(verb, prefix+"/*", handler, middleware)
+		if prefix != "" {
+			husky.add(verb, prefix, handler, middleware)
+		}
+	}
+}
+
+// File serves a single file at endpoint
+func (husky *Husky) File(endpoint string, file string, middleware ...MiddlewareHandler) {
+	handler := func(ctx *CTX) error {
+		return ctx.File(file)
+	}
+
+	husky.add("GET", endpoint, handler, middleware)
+	husky.add("HEAD", endpoint, handler, middleware)
+}
+
+// File serves a file from disk, supporting Range, If-Modified-Since and
+// If-None-Match requests
+func (ctx *CTX) File(file string) error {
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+
+	return ctx.FileFS(os.DirFS(dir), name)
+}
+
+// FileFS serves the named file from fsys
+func (ctx *CTX) FileFS(fsys fs.FS, name string) error {
+	name = path.Clean(name)
+	if !fs.ValidPath(name) {
+		return NotFoundHandler(ctx)
+	}
+
+	err := serveFile(ctx, fsys, name, DefaultStaticConfig.Index)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NotFoundHandler(ctx)
+	}
+
+	return err
+}
+
+// Attachment serves a file from disk as a download named name
+func (ctx *CTX) Attachment(file string, name string) error {
+	return ctx.contentDisposition("attachment", file, name)
+}
+
+// Inline serves a file from disk to be displayed in the browser as name
+func (ctx *CTX) Inline(file string, name string) error {
+	return ctx.contentDisposition("inline", file, name)
+}
+
+func (ctx *CTX) contentDisposition(disposition string, file string, name string) error {
+	ctx.Response.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	return ctx.File(file)
+}
+
+// serveStatic serves name from the configured FS, falling back to the SPA
+// index or directory listings when enabled
+func serveStatic(ctx *CTX, config StaticConfig, name string) error {
+	// cleaning a rooted path removes any .. elements
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(config.FS, name)
+	if err == nil && info.IsDir() {
+		// relative links in directories require a trailing slash
+		if p := ctx.Request.URL.Path; !strings.HasSuffix(p, "/") {
+			return ctx.Redirect(http.StatusMovedPermanently, p+"/")
+		}
+
+		if _, err := fs.Stat(config.FS, path.Join(name, config.Index)); err != nil && config.Browse {
+			return serveListing(ctx, config.FS, name)
+		}
+	}
+
+	err = serveFile(ctx, config.FS, name, config.Index)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if config.SPA && !isAPIPath(ctx.Request.URL.Path, config.APIPrefixes) {
+		return serveFile(ctx, config.FS, config.Index, config.Index)
+	}
+
+	return NotFoundHandler(ctx)
+}
+
+// serveFile serves name with http.ServeContent, directories serve their index
+func serveFile(ctx *CTX, fsys fs.FS, name string, index string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		file.Close()
+		if file, err = fsys.Open(path.Join(name, index)); err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if info, err = file.Stat(); err != nil {
+			return err
+		}
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+
+	etag, err := fileETag(info, content)
+	if err != nil {
+		return err
+	}
+
+	ctx.Response.Header().Set("ETag", etag)
+	http.ServeContent(ctx.Response, ctx.Request, info.Name(), info.ModTime(), content)
+
+	return nil
+}
+
+// fileETag returns a weak ETag from the size and modification time, files
+// without a modification time such as embed.FS files are hashed instead
+func fileETag(info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, nil
+}
+
+// serveListing renders an HTML list of the directory entries
+func serveListing(ctx *CTX, fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	type entry struct {
+		Name string
+		URL  string
+	}
+
+	data := struct {
+		Path    string
+		Entries []entry
+	}{Path: ctx.Request.URL.Path}
+
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() {
+			n += "/"
+		}
+
+		data.Entries = append(data.Entries, entry{Name: n, URL: (&url.URL{Path: n}).String()})
+	}
+
+	var b bytes.Buffer
+	if err := listing.Execute(&b, data); err != nil {
+		return err
+	}
+
+	return ctx.Blob(http.StatusOK, MIMETextHTML+charsetUTF8, b.Bytes())
+}
+
+// isAPIPath reports if the path is under one of the API prefixes
+func isAPIPath(p string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+package husky
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var assets = fstest.MapFS{
+	"index.html":     {Data: []byte("<h1>app</h1>")},
+	"css/site.css":   {Data: []byte("body {}"), ModTime: time.Unix(1500000000, 0)},
+	"docs/guide.txt": {Data: []byte("guide")},
+}
+
+func serve(h *Husky, method string, target string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestStaticFS(t *testing.T) {
+	h := New()
+	h.StaticFS("/assets", assets)
+
+	w := serve(h, "GET", "/assets/css/site.css")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "body {}", w.Body.String())
+	assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	w = serve(h, "GET", "/assets/")
+	assert.Equal(t, "<h1>app</h1>", w.Body.String())
+
+	w = serve(h, "GET", "/assets/missing.js")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestStaticConditionalAndRangeRequests(t *testing.T) {
+	h := New()
+	h.StaticFS("/assets", assets)
+
+	w := serve(h, "GET", "/assets/index.html")
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = serve(h, "GET", "/assets/index.html", "If-None-Match", etag)
+	assert.Equal(t, 304, w.Code)
+
+	w = serve(h, "GET", "/assets/index.html", "Range", "bytes=4-6")
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "app", w.Body.String())
+}
+
+func TestStaticPreventsPathTraversal(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "husky-static")
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "public"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644)
+
+	h := New()
+	h.Static("/assets", filepath.Join(dir, "public"))
+
+	w := serve(h, "GET", "/assets/../secret.txt")
+	assert.NotEqual(t, "secret", w.Body.String())
+
+	w = serve(h, "GET", "/assets/%2e%2e/secret.txt")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestStaticDirectoryListing(t *testing.T) {
+	h := New()
+	h.StaticConfigured("/files", StaticConfig{FS: assets, Browse: true})
+
+	w := serve(h, "GET", "/files/docs")
+	assert.Equal(t, 301, w.Code)
+
+	w = serve(h, "GET", "/files/docs/")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `<a href="guide.txt">guide.txt</a>`)
+
+	h = New()
+	h.StaticFS("/files", assets)
+
+	w = serve(h, "GET", "/files/docs/")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestStaticSPAFallback(t *testing.T) {
+	h := New()
+	h.GET("/api/users", func(ctx *CTX) error {
+		return ctx.JSON(200, "users")
+	})
+	h.StaticConfigured("/", StaticConfig{FS: assets, SPA: true, APIPrefixes: []string{"/api"}})
+
+	w := serve(h, "GET", "/settings/profile")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "<h1>app</h1>", w.Body.String())
+
+	w = serve(h, "GET", "/api/users")
+	assert.Equal(t, `"users"`, w.Body.String())
+
+	w = serve(h, "GET", "/api/unknown")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestFileAndAttachment(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "husky-file")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "report.txt")
+	ioutil.WriteFile(file, []byte("report"), 0644)
+
+	h := New()
+	h.File("/report", file)
+
+	w := serve(h, "GET", "/report")
+	assert.Equal(t, "report", w.Body.String())
+
+	w = httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, c.Attachment(file, "März.txt"))
+	assert.Equal(t, "attachment; filename*=utf-8''M%C3%A4rz.txt", w.Header().Get("Content-Disposition"))
+
+	w = httptest.NewRecorder()
+	c = h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.Inline(file, "report.txt")
+	assert.Equal(t, "inline; filename=report.txt", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
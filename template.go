@@ -0,0 +1,174 @@
+package husky
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ErrRendererNotSet is returned by CTX.Render when Husky has no Renderer
+var ErrRendererNotSet = errors.New("husky: renderer not set")
+
+// Renderer renders named templates for CTX.Render
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, ctx *CTX) error
+}
+
+// TemplateConfig configuration for the html/template TemplateRenderer
+type TemplateConfig struct {
+	FS        fs.FS            // template sources, e.g. os.DirFS("templates") or an embed.FS
+	Extension string           // template file extension, defaults to ".html"
+	Layouts   string           // directory holding layouts, defaults to "layouts"
+	Partials  string           // directory holding partials, defaults to "partials"
+	Layout    string           // layout executed for every page, e.g. "layouts/base"
+	Funcs     template.FuncMap // functions shared by all templates
+	Router    *Router          // routes the url function builds paths for, e.g. h.Router
+	Reload    bool             // re-parse templates on every render, for development
+}
+
+// DefaultTemplateConfig handles the default template configuration for Husky
+var DefaultTemplateConfig = TemplateConfig{
+	Extension: ".html",
+	Layouts:   "layouts",
+	Partials:  "partials",
+}
+
+// TemplateRenderer renders html/template pages with shared layouts and partials.
+// Templates are named by their path without extension, pages are rendered
+// through Layout when it is set, the layout includes the page with
+// {{ template "content" . }}. The url function builds paths of the routes
+// registered on config.Router: {{ url "/users/:id" .ID }}.
+type TemplateRenderer struct {
+	config TemplateConfig
+	mu     sync.RWMutex
+	pages  map[string]*template.Template
+}
+
+// NewTemplateRenderer parses all templates of config.FS
+func NewTemplateRenderer(config TemplateConfig) (*TemplateRenderer, error) {
+	if config.FS == nil {
+		return nil, errors.New("husky: template renderer requires a FS")
+	}
+
+	if config.Extension == "" {
+		config.Extension = DefaultTemplateConfig.Extension
+	}
+
+	if config.Layouts == "" {
+		config.Layouts = DefaultTemplateConfig.Layouts
+	}
+
+	if config.Partials == "" {
+		config.Partials = DefaultTemplateConfig.Partials
+	}
+
+	renderer := &TemplateRenderer{config: config}
+	if err := renderer.load(); err != nil {
+		return nil, err
+	}
+
+	return renderer, nil
+}
+
+// Render executes the page called name, reloading templates first when
+// Reload is enabled
+func (renderer *TemplateRenderer) Render(w io.Writer, name string, data interface{}, ctx *CTX) error {
+	if renderer.config.Reload {
+		if err := renderer.load(); err != nil {
+			return err
+		}
+	}
+
+	renderer.mu.RLock()
+	page, ok := renderer.pages[name]
+	renderer.mu.RUnlock()
+
+	if !ok {
+		return errors.New("husky: template " + name + " not found")
+	}
+
+	if layout := renderer.config.Layout; layout != "" {
+		return page.ExecuteTemplate(w, layout, data)
+	}
+
+	return page.ExecuteTemplate(w, name, data)
+}
+
+// load parses the layouts and partials into a base set which is cloned for
+// every page so pages can define the same blocks
+func (renderer *TemplateRenderer) load() error {
+	config := renderer.config
+
+	funcs := template.FuncMap{"url": routeURL(config.Router)}
+	for k, v := range config.Funcs {
+		funcs[k] = v
+	}
+
+	base := template.New("").Funcs(funcs)
+	sources := make(map[string]string)
+
+	err := fs.WalkDir(config.FS, ".", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(file) != config.Extension {
+			return err
+		}
+
+		b, err := fs.ReadFile(config.FS, file)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(file, config.Extension)
+		if isUnder(file, config.Layouts) || isUnder(file, config.Partials) {
+			_, err = base.New(name).Parse(string(b))
+			return err
+		}
+
+		sources[name] = string(b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if config.Layout != "" && base.Lookup(config.Layout) == nil {
+		return errors.New("husky: layout " + config.Layout + " not found")
+	}
+
+	pages := make(map[string]*template.Template, len(sources))
+	for name, source := range sources {
+		page, err := base.Clone()
+		if err != nil {
+			return err
+		}
+
+		if pages[name], err = page.New(name).Parse(source); err != nil {
+			return err
+		}
+	}
+
+	renderer.mu.Lock()
+	renderer.pages = pages
+	renderer.mu.Unlock()
+
+	return nil
+}
+
+// routeURL returns the url template function building paths of the routes
+// registered on router
+func routeURL(router *Router) func(endpoint string, params ...interface{}) (string, error) {
+	return func(endpoint string, params ...interface{}) (string, error) {
+		if router == nil {
+			return "", errors.New("husky: url requires a TemplateConfig Router")
+		}
+		return router.URL(endpoint, params...)
+	}
+}
+
+// isUnder reports if file is inside dir
+func isUnder(file string, dir string) bool {
+	return strings.HasPrefix(file, strings.TrimSuffix(dir, "/")+"/")
+}
@@ -0,0 +1,115 @@
+package husky
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var templates = fstest.MapFS{
+	"layouts/base.html":  {Data: []byte(`<html>{{ template "partials/nav" . }}{{ template "content" . }}</html>`)},
+	"partials/nav.html":  {Data: []byte(`<nav>{{ upper .Site }}</nav>`)},
+	"users/show.html":    {Data: []byte(`{{ define "content" }}<a href="{{ url "/users/:id" .ID }}">{{ .Name }}</a>{{ end }}`)},
+	"plain.html":         {Data: []byte(`<p>{{ .Name }}</p>`)},
+	"assets/ignored.css": {Data: []byte(`body {}`)},
+}
+
+func newRenderer(t *testing.T, router *Router, layout string) *TemplateRenderer {
+	renderer, err := NewTemplateRenderer(TemplateConfig{
+		FS:     templates,
+		Layout: layout,
+		Funcs:  template.FuncMap{"upper": strings.ToUpper},
+		Router: router,
+	})
+	assert.NoError(t, err)
+
+	return renderer
+}
+
+func TestRenderWithLayout(t *testing.T) {
+	h := New()
+	h.GET("/users/:id", handler)
+	h.Renderer = newRenderer(t, h.Router, "layouts/base")
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	err := c.Render(200, "users/show", map[string]interface{}{"Site": "husky", "ID": 5, "Name": "<John>"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "text/html;charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `<html><nav>HUSKY</nav><a href="/users/5">&lt;John&gt;</a></html>`, w.Body.String())
+	}
+}
+
+func TestRenderWithoutLayout(t *testing.T) {
+	h := New()
+	h.Renderer = newRenderer(t, h.Router, "")
+
+	w := httptest.NewRecorder()
+	c := h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if assert.NoError(t, c.Render(201, "plain", map[string]string{"Name": "John"})) {
+		assert.Equal(t, 201, w.Code)
+		assert.Equal(t, `<p>John</p>`, w.Body.String())
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	h := New()
+
+	c := h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, ErrRendererNotSet, c.Render(200, "plain", nil))
+
+	h.Renderer = newRenderer(t, h.Router, "")
+	w := httptest.NewRecorder()
+	c = h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Error(t, c.Render(200, "missing", nil))
+	assert.False(t, c.Response.Committed)
+
+	// url only builds paths of registered routes
+	h.Renderer = newRenderer(t, h.Router, "layouts/base")
+	w = httptest.NewRecorder()
+	c = h.NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Error(t, c.Render(200, "users/show", map[string]interface{}{"ID": 5}))
+	assert.False(t, c.Response.Committed)
+
+	_, err := NewTemplateRenderer(TemplateConfig{FS: templates, Layout: "layouts/missing"})
+	assert.Error(t, err)
+}
+
+func TestRenderReload(t *testing.T) {
+	fsys := fstest.MapFS{"page.html": {Data: []byte(`v1`)}}
+
+	h := New()
+	h.Renderer, _ = NewTemplateRenderer(TemplateConfig{FS: fsys, Reload: true})
+
+	fsys["page.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+
+	w := httptest.NewRecorder()
+	h.NewContext(w, httptest.NewRequest("GET", "/", nil)).Render(200, "page", nil)
+
+	assert.Equal(t, "v2", w.Body.String())
+}
+
+func TestRouterURL(t *testing.T) {
+	h := New()
+	h.GET("/users/:id/posts/:post", handler)
+
+	url, err := h.Router.URL("/users/:id/posts/:post", 1, "hello world")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/users/1/posts/hello%20world", url)
+	}
+
+	_, err = h.Router.URL("/missing")
+	assert.Error(t, err)
+
+	_, err = BuildURL("/users/:id")
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package trace
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchSpanExporter is implemented by exporters shipping many spans per call,
+// BatchExporter uses it instead of Export when available
+type BatchSpanExporter interface {
+	ExportBatch(spans []*Span)
+}
+
+// BatchConfig configuration for BatchExporter
+type BatchConfig struct {
+	Size      int           // spans per batch
+	Interval  time.Duration // maximum delay of a queued span
+	QueueSize int           // queued spans, further spans are dropped
+}
+
+// DefaultBatchConfig handles the default BatchExporter configuration
+var DefaultBatchConfig = BatchConfig{
+	Size:      512,
+	Interval:  5 * time.Second,
+	QueueSize: 2048,
+}
+
+// BatchExporter queues finished spans and exports them in batches from a
+// background goroutine, so Span.Finish never waits on the tracing backend
+type BatchExporter struct {
+	exporter Exporter
+	config   BatchConfig
+	queue    chan *Span
+	flush    chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	close    sync.Once
+	dropped  int64
+}
+
+// NewBatchExporter starts a BatchExporter shipping spans to exporter, Close
+// exports the queued spans and stops it. Exporters are called synchronously
+// unless they are wrapped with NewBatchExporter.
+func NewBatchExporter(exporter Exporter, config BatchConfig) *BatchExporter {
+	if config.Size <= 0 {
+		config.Size = DefaultBatchConfig.Size
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = DefaultBatchConfig.Interval
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultBatchConfig.QueueSize
+	}
+
+	batcher := &BatchExporter{
+		exporter: exporter,
+		config:   config,
+		queue:    make(chan *Span, config.QueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go batcher.run()
+
+	return batcher
+}
+
+// Export queues the span, it is dropped when the queue is full or the
+// exporter is closed
+func (batcher *BatchExporter) Export(span *Span) {
+	select {
+	case <-batcher.done:
+		atomic.AddInt64(&batcher.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case batcher.queue <- span:
+	default:
+		atomic.AddInt64(&batcher.dropped, 1)
+	}
+}
+
+// Flush exports the queued spans and waits until they are exported
+func (batcher *BatchExporter) Flush() {
+	reply := make(chan struct{})
+
+	select {
+	case batcher.flush <- reply:
+		<-reply
+	case <-batcher.stopped:
+	}
+}
+
+// Close exports the queued spans and stops the background goroutine
+func (batcher *BatchExporter) Close() error {
+	batcher.close.Do(func() {
+		close(batcher.done)
+	})
+	<-batcher.stopped
+
+	return nil
+}
+
+// Dropped returns the number of spans dropped because the queue was full
+func (batcher *BatchExporter) Dropped() int64 {
+	return atomic.LoadInt64(&batcher.dropped)
+}
+
+// run collects queued spans until a batch is full or the interval passed
+func (batcher *BatchExporter) run() {
+	defer close(batcher.stopped)
+
+	ticker := time.NewTicker(batcher.config.Interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batcher.config.Size)
+
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if exporter, ok := batcher.exporter.(BatchSpanExporter); ok {
+			exporter.ExportBatch(batch)
+		} else {
+			for _, span := range batch {
+				batcher.exporter.Export(span)
+			}
+		}
+
+		batch = make([]*Span, 0, batcher.config.Size)
+	}
+
+	add := func(span *Span) {
+		batch = append(batch, span)
+		if len(batch) >= batcher.config.Size {
+			export()
+		}
+	}
+
+	drain := func() {
+		for {
+			select {
+			case span := <-batcher.queue:
+				add(span)
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case span := <-batcher.queue:
+			add(span)
+		case <-ticker.C:
+			export()
+		case reply := <-batcher.flush:
+			drain()
+			close(reply)
+		case <-batcher.done:
+			drain()
+			return
+		}
+	}
+}
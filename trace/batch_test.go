@@ -0,0 +1,66 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder records the size of every exported batch
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (r *batchRecorder) Export(span *Span) {
+	r.ExportBatch([]*Span{span})
+}
+
+func (r *batchRecorder) ExportBatch(spans []*Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, len(spans))
+}
+
+func TestBatchExporterExportsInBackground(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	batcher := NewBatchExporter(exporter, BatchConfig{Interval: time.Hour})
+	tracer := NewTracer(batcher)
+
+	_, span := tracer.Start(context.Background(), "GET /", SpanKindServer)
+	span.Finish()
+	assert.Empty(t, exporter.Spans())
+
+	batcher.Flush()
+	assert.Equal(t, []*Span{span}, exporter.Spans())
+
+	_, span = tracer.Start(context.Background(), "GET /", SpanKindServer)
+	span.Finish()
+	assert.NoError(t, batcher.Close())
+	assert.Len(t, exporter.Spans(), 2)
+
+	// spans finished after Close are dropped
+	_, span = tracer.Start(context.Background(), "GET /", SpanKindServer)
+	span.Finish()
+	batcher.Flush()
+	assert.Len(t, exporter.Spans(), 2)
+	assert.Equal(t, int64(1), batcher.Dropped())
+}
+
+func TestBatchExporterBatches(t *testing.T) {
+	recorder := &batchRecorder{}
+	batcher := NewBatchExporter(recorder, BatchConfig{Size: 2, Interval: time.Hour})
+	tracer := NewTracer(batcher)
+
+	for i := 0; i < 5; i++ {
+		_, span := tracer.Start(context.Background(), "GET /", SpanKindServer)
+		span.Finish()
+	}
+	batcher.Close()
+
+	assert.Equal(t, []int{2, 2, 1}, recorder.batches)
+}
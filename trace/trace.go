@@ -0,0 +1,353 @@
+// Package trace records spans compatible with OpenTelemetry and propagates
+// them with the W3C Trace Context headers
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTraceparent is returned when a traceparent header can not be parsed
+var ErrInvalidTraceparent = errors.New("trace: invalid traceparent")
+
+// W3C Trace Context headers
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// FlagSampled marks a sampled trace in the trace flags
+const FlagSampled = 0x01
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// String returns the hex encoded trace ID
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports if the trace ID is not all zeros
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the hex encoded span ID
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports if the span ID is not all zeros
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span propagated between services
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	Remote     bool
+}
+
+// IsValid reports if both IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled reports if the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the traceparent header value
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parses a traceparent header value
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, ErrInvalidTraceparent
+	}
+
+	// version 00 has exactly four fields, later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, ErrInvalidTraceparent
+	}
+
+	if _, err := hex.Decode(make([]byte, 1), []byte(parts[0])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) {
+		return sc, ErrInvalidTraceparent
+	}
+
+	flags := make([]byte, 1)
+	if !decodeHex(flags, parts[3]) {
+		return sc, ErrInvalidTraceparent
+	}
+	sc.Flags = flags[0]
+
+	if !sc.IsValid() {
+		return sc, ErrInvalidTraceparent
+	}
+
+	sc.Remote = true
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex of exactly the length of dst
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Extract returns the remote span context of the request headers
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	sc.TraceState = strings.Join(header.Values(TracestateHeader), ",")
+	return sc, true
+}
+
+// Inject sets the trace headers of the span in ctx on an outgoing request
+func Inject(ctx context.Context, header http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+
+	header.Set(TraceparentHeader, span.SpanContext.Traceparent())
+	if span.SpanContext.TraceState != "" {
+		header.Set(TracestateHeader, span.SpanContext.TraceState)
+	}
+}
+
+// SpanKind describes the relation of a span to its parent and children
+type SpanKind int
+
+// Span kinds as defined by OpenTelemetry
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode is the status of a finished span
+type StatusCode int
+
+// Span status codes as defined by OpenTelemetry
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Event is a timestamped annotation of a span
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// Span is a single timed operation of a trace
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanContext
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]interface{}
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+// SetAttribute sets an attribute of the span
+func (span *Span) SetAttribute(key string, value interface{}) {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	span.Attributes[key] = value
+}
+
+// SetStatus sets the status of the span
+func (span *Span) SetStatus(code StatusCode, message string) {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	span.Status = code
+	span.StatusMessage = message
+}
+
+// RecordError adds an exception event for err and marks the span as failed
+func (span *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	span.Events = append(span.Events, Event{
+		Name:       "exception",
+		Time:       time.Now(),
+		Attributes: map[string]interface{}{"exception.message": err.Error()},
+	})
+	span.Status = StatusError
+	span.StatusMessage = err.Error()
+}
+
+// Finish ends the span and exports it when sampled, later calls are ignored
+func (span *Span) Finish() {
+	span.mu.Lock()
+	if span.ended {
+		span.mu.Unlock()
+		return
+	}
+	span.ended = true
+	span.End = time.Now()
+	span.mu.Unlock()
+
+	if span.SpanContext.IsSampled() && span.tracer != nil && span.tracer.Exporter != nil {
+		span.tracer.Exporter.Export(span)
+	}
+}
+
+// Exporter ships finished spans to a tracing backend
+type Exporter interface {
+	Export(span *Span)
+}
+
+// Tracer starts spans and exports them once finished
+type Tracer struct {
+	Exporter Exporter
+}
+
+// NewTracer creates a tracer exporting spans to exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{Exporter: exporter}
+}
+
+// Start starts a span as child of the span or remote span context in ctx
+// and returns a context holding the new span
+func (tracer *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		Parent:     parent,
+		Start:      time.Now(),
+		Attributes: make(map[string]interface{}),
+		tracer:     tracer,
+	}
+
+	if parent.IsValid() {
+		span.SpanContext.TraceID = parent.TraceID
+		span.SpanContext.Flags = parent.Flags
+		span.SpanContext.TraceState = parent.TraceState
+	} else {
+		rand.Read(span.SpanContext.TraceID[:])
+		span.SpanContext.Flags = FlagSampled
+	}
+	rand.Read(span.SpanContext.SpanID[:])
+
+	return ContextWithSpan(ctx, span), span
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// ContextWithSpan returns a context holding the span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// ContextWithRemoteSpanContext returns a context holding a span context
+// received from another service
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanFromContext returns the span in ctx or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the span context of the span in ctx or the
+// remote span context when there is no local span
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext
+	}
+
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// InMemoryExporter keeps exported spans in memory for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter creates an empty in memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// Export stores the span
+func (e *InMemoryExporter) Export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the exported spans in export order
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]*Span(nil), e.spans...)
+}
+
+// Reset removes all exported spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(traceparent)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.True(t, sc.Remote)
+	assert.Equal(t, traceparent, sc.Traceparent())
+}
+
+func TestParseTraceparentRejectsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(value)
+		assert.Equal(t, ErrInvalidTraceparent, err, value)
+	}
+
+	// later versions may add fields
+	_, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.NoError(t, err)
+}
+
+func TestStartContinuesRemoteTrace(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	header := http.Header{}
+	header.Set(TraceparentHeader, traceparent)
+	header.Set(TracestateHeader, "vendor=value")
+
+	remote, ok := Extract(header)
+	assert.True(t, ok)
+
+	ctx, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "GET /users/:id", SpanKindServer)
+	_, child := tracer.Start(ctx, "query", SpanKindInternal)
+	child.Finish()
+	span.Finish()
+	span.Finish()
+
+	assert.Equal(t, remote.TraceID, span.SpanContext.TraceID)
+	assert.Equal(t, remote.SpanID, span.Parent.SpanID)
+	assert.Equal(t, span.SpanContext.SpanID, child.Parent.SpanID)
+	assert.Equal(t, []*Span{child, span}, exporter.Spans())
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	assert.Equal(t, span.SpanContext.Traceparent(), outgoing.Get(TraceparentHeader))
+	assert.Equal(t, "vendor=value", outgoing.Get(TracestateHeader))
+}
+
+func TestUnsampledSpansAreNotExported(t *testing.T) {
+	exporter := NewInMemoryExporter()
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, span := NewTracer(exporter).Start(ContextWithRemoteSpanContext(context.Background(), remote), "GET /", SpanKindServer)
+	span.Finish()
+
+	assert.Empty(t, exporter.Spans())
+}
@@ -0,0 +1,80 @@
+package husky
+
+import "context"
+
+// valueKey wraps keys passed to Set so they never collide with context keys
+// of other packages
+type valueKey struct {
+	key interface{}
+}
+
+// storedValue wraps values passed to Set so a stored nil is told apart from
+// a missing value
+type storedValue struct {
+	value interface{}
+}
+
+// Set stores a value for the rest of the request, the value is added to
+// ctx.Context() so it is also visible to context.Context consumers through
+// Value. Keys must be comparable.
+func (ctx *CTX) Set(key interface{}, value interface{}) {
+	ctx.SetContext(context.WithValue(ctx.Context(), valueKey{key}, storedValue{value}))
+}
+
+// Get returns the value stored with Set, ok is true for stored nil values
+func (ctx *CTX) Get(key interface{}) (interface{}, bool) {
+	return Value(ctx.Context(), key)
+}
+
+// Value returns the value stored with ctx.Set from a context.Context
+func Value(c context.Context, key interface{}) (interface{}, bool) {
+	stored, ok := c.Value(valueKey{key}).(storedValue)
+	return stored.value, ok
+}
+
+// Key is a typed key for values stored on the CTX
+//
+//	var UserKey = husky.NewKey[*User]("user")
+//	UserKey.Set(ctx, user)
+//	user, ok := UserKey.Get(ctx)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a typed key, every call returns a distinct key
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String returns the name of the key
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// Set stores value on the CTX
+func (k *Key[T]) Set(ctx *CTX, value T) {
+	ctx.Set(k, value)
+}
+
+// Get returns the value stored on the CTX
+func (k *Key[T]) Get(ctx *CTX) (T, bool) {
+	return k.From(ctx.Context())
+}
+
+// From returns the value stored on the CTX from a context.Context
+func (k *Key[T]) From(c context.Context) (T, bool) {
+	var zero T
+
+	stored, ok := Value(c, k)
+	if !ok {
+		return zero, false
+	}
+
+	// a nil stored for an interface type is the zero value
+	if stored == nil {
+		return zero, true
+	}
+
+	value, ok := stored.(T)
+	return value, ok
+}
@@ -0,0 +1,106 @@
+package husky
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenant struct {
+	ID string
+}
+
+var tenantKey = NewKey[*tenant]("tenant")
+
+func TestSetAndGet(t *testing.T) {
+	h := New()
+	c := h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	_, ok := c.Get("user")
+	assert.False(t, ok)
+
+	c.Set("user", "john")
+	c.Set(42, []string{"admin"})
+
+	user, ok := c.Get("user")
+	assert.True(t, ok)
+	assert.Equal(t, "john", user)
+
+	roles, _ := c.Get(42)
+	assert.Equal(t, []string{"admin"}, roles)
+
+	// plain context.Context consumers see the values
+	user, ok = Value(c.Context(), "user")
+	assert.True(t, ok)
+	assert.Equal(t, "john", user)
+
+	// string keys do not collide with context keys of other packages
+	assert.Nil(t, c.Context().Value("user"))
+}
+
+func TestSetNil(t *testing.T) {
+	h := New()
+	c := h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	// a stored nil is present
+	c.Set("user", nil)
+	user, ok := c.Get("user")
+	assert.True(t, ok)
+	assert.Nil(t, user)
+
+	tenantKey.Set(c, nil)
+	current, ok := tenantKey.Get(c)
+	assert.True(t, ok)
+	assert.Nil(t, current)
+
+	errKey := NewKey[error]("error")
+	_, ok = errKey.Get(c)
+	assert.False(t, ok)
+
+	errKey.Set(c, nil)
+	err, ok := errKey.Get(c)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestTypedKeys(t *testing.T) {
+	h := New()
+
+	h.GET("/", func(ctx *CTX) error {
+		current, ok := tenantKey.Get(ctx)
+		if !ok {
+			return ctx.String(400, "no tenant")
+		}
+
+		return ctx.String(200, current.ID+" "+tenantFrom(ctx.Context()))
+	}, func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			tenantKey.Set(ctx, &tenant{ID: ctx.GetHeader("X-Tenant")})
+			return next(ctx)
+		}
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Tenant", "acme")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "acme acme", w.Body.String())
+
+	// keys with the same name are distinct
+	other := NewKey[*tenant]("tenant")
+	c := h.NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	tenantKey.Set(c, &tenant{ID: "acme"})
+
+	_, ok := other.Get(c)
+	assert.False(t, ok)
+	assert.Equal(t, "tenant", other.String())
+}
+
+// tenantFrom reads the tenant like a library only knowing context.Context
+func tenantFrom(c context.Context) string {
+	current, _ := tenantKey.From(c)
+	return current.ID
+}
@@ -0,0 +1,535 @@
+package husky
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket message types as defined by RFC 6455
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket close codes as defined by RFC 6455
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatusReceived = 1005
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrHijackUnsupported is returned when the response writer can not be hijacked
+var ErrHijackUnsupported = errors.New("husky: response does not support hijacking")
+
+// ErrCloseSent is returned when writing after the close frame was sent
+var ErrCloseSent = errors.New("husky: websocket close sent")
+
+// CloseError is returned by ReadMessage when the peer closes the connection
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "husky: websocket closed with code " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+// WebSocketHandler handles an upgraded WebSocket connection
+type WebSocketHandler func(*CTX, *Conn) error
+
+// WebSocketConfig configuration for WebSocket routes
+type WebSocketConfig struct {
+	CheckOrigin  func(r *http.Request) bool // defaults to allowing same origin and non browser clients
+	Subprotocols []string                   // supported subprotocols in order of preference
+	ReadLimit    int64                      // maximum message size in bytes, defaults to 1MB
+	WriteTimeout time.Duration              // deadline for each write, defaults to 10s
+	PingInterval time.Duration              // interval of keep alive pings, 0 disables them
+}
+
+// maxReadLimit bounds reads when no ReadLimit is configured at all
+const maxReadLimit = 1 << 20
+
+// DefaultWebSocketConfig handles the default WebSocket configuration for Husky
+var DefaultWebSocketConfig = WebSocketConfig{
+	ReadLimit:    maxReadLimit,
+	WriteTimeout: 10 * time.Second,
+}
+
+// WebSocket adds a WebSocket route, the handshake runs after the route
+// middleware so authentication and logging apply as for other routes
+func (husky *Husky) WebSocket(endpoint string, handler WebSocketHandler, middleware ...MiddlewareHandler) {
+	husky.WebSocketConfigured(endpoint, DefaultWebSocketConfig, handler, middleware...)
+}
+
+// WebSocketConfigured adds a WebSocket route with the given configuration
+func (husky *Husky) WebSocketConfigured(endpoint string, config WebSocketConfig, handler WebSocketHandler, middleware ...MiddlewareHandler) {
+	husky.add("GET", endpoint, func(ctx *CTX) error {
+		conn, err := ctx.Upgrade(config)
+		if err != nil {
+			return nil
+		}
+		defer conn.Close()
+
+		err = handler(ctx, conn)
+		if closeErr, ok := err.(*CloseError); ok && (closeErr.Code == CloseNormalClosure || closeErr.Code == CloseGoingAway) {
+			return nil
+		}
+
+		return err
+	}, middleware)
+}
+
+// Upgrade performs the RFC 6455 handshake, failed handshakes are answered
+// with an HTTP error before the error is returned. Zero ReadLimit and
+// WriteTimeout use the DefaultWebSocketConfig values.
+func (ctx *CTX) Upgrade(config WebSocketConfig) (*Conn, error) {
+	req := ctx.Request
+
+	if config.ReadLimit <= 0 {
+		config.ReadLimit = DefaultWebSocketConfig.ReadLimit
+	}
+
+	// messages are buffered in memory, so reads are always bounded
+	if config.ReadLimit <= 0 {
+		config.ReadLimit = maxReadLimit
+	}
+
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = DefaultWebSocketConfig.WriteTimeout
+	}
+
+	if req.Method != "GET" || !headerContains(req.Header, "Connection", "upgrade") || !headerContains(req.Header, "Upgrade", "websocket") {
+		ctx.HTTPError(http.StatusBadRequest, "Bad Request")
+		return nil, errors.New("husky: not a websocket handshake")
+	}
+
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		ctx.SetHeader("Sec-WebSocket-Version", "13")
+		ctx.HTTPError(http.StatusUpgradeRequired, "Upgrade Required")
+		return nil, errors.New("husky: unsupported websocket version")
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		ctx.HTTPError(http.StatusBadRequest, "Bad Request")
+		return nil, errors.New("husky: invalid websocket key")
+	}
+
+	checkOrigin := config.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+
+	if !checkOrigin(req) {
+		ctx.HTTPError(http.StatusForbidden, "Forbidden")
+		return nil, errors.New("husky: websocket origin not allowed")
+	}
+
+	subprotocol := negotiateSubprotocol(req.Header, config.Subprotocols)
+
+	netConn, rw, err := ctx.Response.Hijack()
+	if err != nil {
+		ctx.HTTPError(http.StatusInternalServerError, "Internal Server Error")
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if subprotocol != "" {
+		response += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	response += "\r\n"
+
+	if config.WriteTimeout > 0 {
+		netConn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+	}
+
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	ctx.Response.Status = http.StatusSwitchingProtocols
+
+	conn := &Conn{
+		conn:         netConn,
+		reader:       rw.Reader,
+		subprotocol:  subprotocol,
+		readLimit:    config.ReadLimit,
+		writeTimeout: config.WriteTimeout,
+		done:         make(chan struct{}),
+	}
+
+	if config.PingInterval > 0 {
+		go conn.keepAlive(config.PingInterval)
+	}
+
+	return conn, nil
+}
+
+// Conn is a message oriented WebSocket connection
+// Reads must happen from a single goroutine, writes are safe for concurrent use
+type Conn struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	subprotocol  string
+	readLimit    int64
+	writeTimeout time.Duration
+	writeMu      sync.Mutex
+	closeSent    bool
+	closeOnce    sync.Once
+	done         chan struct{}
+	pongHandler  func(data []byte)
+}
+
+// Subprotocol returns the negotiated subprotocol
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the address of the client
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline sets the deadline for the next reads
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// OnPong sets a function called for every pong received
+func (c *Conn) OnPong(handler func(data []byte)) {
+	c.pongHandler = handler
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered
+// automatically, a close frame is answered and returned as a *CloseError.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil && err != ErrCloseSent {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler(payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.handleClose(payload)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			messageType = opcode
+		case 0:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(data)+len(payload)) > c.readLimit {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		data = append(data, payload...)
+
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(data) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid utf-8")
+			}
+
+			return messageType, data, nil
+		}
+	}
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage sends a text or binary message
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return errors.New("husky: invalid websocket message type")
+	}
+
+	return c.writeFrame(messageType, data)
+}
+
+// WriteJSON sends v encoded as JSON in a text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.writeFrame(TextMessage, b)
+}
+
+// Ping sends a ping, the client answers with a pong
+func (c *Conn) Ping(data []byte) error {
+	return c.writeFrame(PingMessage, data)
+}
+
+// Close sends a normal closure and closes the connection
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode sends a close frame with code and reason and closes the connection
+func (c *Conn) CloseWithCode(code int, reason string) error {
+	err := c.writeClose(code, reason)
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+		if closeErr := c.conn.Close(); err == nil || err == ErrCloseSent {
+			err = closeErr
+		}
+	})
+
+	if err == ErrCloseSent {
+		return nil
+	}
+
+	return err
+}
+
+// readFrame reads and unmasks a single frame
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.reader, header[:]); err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var b [2]byte
+		if _, err = io.ReadFull(c.reader, b[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err = io.ReadFull(c.reader, b[:]); err != nil {
+			return
+		}
+		if b[0]&0x80 != 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, "invalid frame length")
+		}
+		length = int64(binary.BigEndian.Uint64(b[:]))
+	}
+
+	if opcode >= CloseMessage && (!fin || length > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+
+	if length > c.readLimit {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.reader, mask[:]); err != nil {
+		return
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return
+}
+
+// writeFrame sends a single unmasked frame
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrCloseSent
+	}
+
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|byte(opcode))
+
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 126, byte(length>>8), byte(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	if c.writeTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// writeClose sends a close frame with the code and reason
+func (c *Conn) writeClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+
+	return c.writeFrame(CloseMessage, append(payload, reason...))
+}
+
+// handleClose answers a close frame from the client
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+	}
+
+	code := closeErr.Code
+	if code == CloseNoStatusReceived {
+		code = CloseNormalClosure
+	}
+	c.writeClose(code, "")
+
+	return closeErr
+}
+
+// fail closes the connection after a protocol violation by the client
+func (c *Conn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	return &CloseError{Code: code, Text: reason}
+}
+
+// keepAlive pings the client until the connection is closed
+func (c *Conn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Ping(nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// acceptKey computes the Sec-WebSocket-Accept value for the client key
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// negotiateSubprotocol returns the first supported protocol offered by the client
+func negotiateSubprotocol(header http.Header, supported []string) string {
+	offered := make(map[string]bool)
+	for _, value := range header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			offered[strings.TrimSpace(protocol)] = true
+		}
+	}
+
+	for _, protocol := range supported {
+		if offered[protocol] {
+			return protocol
+		}
+	}
+
+	return ""
+}
+
+// headerContains reports if the comma separated header contains the token
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sameOrigin allows requests without an Origin header or where the Origin
+// host matches the Host header
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,246 @@
+package husky
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wsDial performs the client handshake against the test server
+func wsDial(t *testing.T, server *httptest.Server, path string, header http.Header) (net.Conn, *bufio.Reader, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Write(conn)
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, reader, res
+}
+
+// wsWrite sends a masked client frame
+func wsWrite(conn net.Conn, fin bool, opcode int, payload []byte) {
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+
+	frame := []byte{b0}
+	if len(payload) <= 125 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126, byte(len(payload)>>8), byte(len(payload)))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, c := range payload {
+		frame = append(frame, c^mask[i%4])
+	}
+
+	conn.Write(frame)
+}
+
+// wsRead reads an unmasked server frame
+func wsRead(reader *bufio.Reader) (int, []byte) {
+	var header [2]byte
+	io.ReadFull(reader, header[:])
+
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var b [2]byte
+		io.ReadFull(reader, b[:])
+		length = int(binary.BigEndian.Uint16(b[:]))
+	}
+
+	payload := make([]byte, length)
+	io.ReadFull(reader, payload)
+
+	return int(header[0] & 0x0f), payload
+}
+
+func echo(ctx *CTX, conn *Conn) error {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			return err
+		}
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	h := New()
+	h.WebSocketConfigured("/ws", WebSocketConfig{Subprotocols: []string{"chat", "json"}}, echo)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, _, res := wsDial(t, server, "/ws", http.Header{"Sec-Websocket-Protocol": {"json, chat"}})
+	defer conn.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+	assert.Equal(t, "chat", res.Header.Get("Sec-WebSocket-Protocol"))
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	h := New()
+	h.WebSocket("/ws", echo)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, reader, _ := wsDial(t, server, "/ws", nil)
+	defer conn.Close()
+
+	wsWrite(conn, true, TextMessage, []byte("hello"))
+	opcode, payload := wsRead(reader)
+	assert.Equal(t, TextMessage, opcode)
+	assert.Equal(t, "hello", string(payload))
+
+	// fragmented message with a ping in between
+	wsWrite(conn, false, BinaryMessage, []byte("hel"))
+	wsWrite(conn, true, PingMessage, []byte("p"))
+	wsWrite(conn, true, 0, []byte(strings.Repeat("o", 200)))
+
+	opcode, payload = wsRead(reader)
+	assert.Equal(t, PongMessage, opcode)
+	assert.Equal(t, "p", string(payload))
+
+	opcode, payload = wsRead(reader)
+	assert.Equal(t, BinaryMessage, opcode)
+	assert.Equal(t, "hel"+strings.Repeat("o", 200), string(payload))
+
+	wsWrite(conn, true, CloseMessage, []byte{0x03, 0xe8})
+	opcode, payload = wsRead(reader)
+	assert.Equal(t, CloseMessage, opcode)
+	assert.Equal(t, []byte{0x03, 0xe8}, payload)
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	h := New()
+	h.WebSocketConfigured("/ws", WebSocketConfig{ReadLimit: 4}, echo)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, reader, _ := wsDial(t, server, "/ws", nil)
+	defer conn.Close()
+
+	wsWrite(conn, true, TextMessage, []byte("too long"))
+	opcode, payload := wsRead(reader)
+	assert.Equal(t, CloseMessage, opcode)
+	assert.Equal(t, CloseMessageTooBig, int(binary.BigEndian.Uint16(payload)))
+}
+
+func TestWebSocketDefaultReadLimit(t *testing.T) {
+	h := New()
+	h.WebSocketConfigured("/ws", WebSocketConfig{}, echo)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, reader, _ := wsDial(t, server, "/ws", nil)
+	defer conn.Close()
+
+	// a frame announcing 1TB is rejected before anything is allocated
+	frame := []byte{0x80 | TextMessage, 0x80 | 127, 0, 0, 1, 0, 0, 0, 0, 0, 1, 2, 3, 4}
+	conn.Write(frame)
+
+	opcode, payload := wsRead(reader)
+	assert.Equal(t, CloseMessage, opcode)
+	assert.Equal(t, CloseMessageTooBig, int(binary.BigEndian.Uint16(payload)))
+}
+
+func TestWebSocketRejectsInvalidHandshake(t *testing.T) {
+	h := New()
+	h.WebSocket("/ws", echo)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, _, res := wsDial(t, server, "/ws", http.Header{"Origin": {"http://evil.example"}})
+	conn.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	conn, _, res = wsDial(t, server, "/ws", http.Header{"Sec-Websocket-Version": {"8"}})
+	conn.Close()
+	assert.Equal(t, http.StatusUpgradeRequired, res.StatusCode)
+	assert.Equal(t, "13", res.Header.Get("Sec-WebSocket-Version"))
+
+	res, err := http.Get(server.URL + "/ws")
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestWebSocketRunsRouteMiddleware(t *testing.T) {
+	h := New()
+
+	auth := func(next Handler) Handler {
+		return func(ctx *CTX) error {
+			if ctx.GetHeader("Authorization") != "secret" {
+				return ctx.JSON(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			return next(ctx)
+		}
+	}
+
+	h.WebSocket("/ws", func(ctx *CTX, conn *Conn) error {
+		return conn.WriteMessage(TextMessage, []byte("welcome"))
+	}, auth)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	conn, _, res := wsDial(t, server, "/ws", nil)
+	conn.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	conn, reader, res := wsDial(t, server, "/ws", http.Header{"Authorization": {"secret"}})
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	_, payload := wsRead(reader)
+	assert.Equal(t, "welcome", string(payload))
+
+	// the handler returned, the connection is closed normally
+	opcode, payload := wsRead(reader)
+	assert.Equal(t, CloseMessage, opcode)
+	assert.Equal(t, CloseNormalClosure, int(binary.BigEndian.Uint16(payload)))
+}
+
+func TestResponseHijackUnsupported(t *testing.T) {
+	r := NewResponse(httptest.NewRecorder())
+
+	_, _, err := r.Hijack()
+	assert.True(t, errors.Is(err, ErrHijackUnsupported))
+}